@@ -0,0 +1,379 @@
+// Package dice parses the dice expressions found throughout the Open5e
+// data, e.g. monster hit dice "18d10+36", class hit dice "1d12" and damage
+// "2d6 + 5", and answers questions about them: their bounds, average and
+// distribution, or a roll.
+package dice
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Limits on what Parse accepts, so that a distribution can always be
+// computed in reasonable time.
+const (
+	maxDice      = 100
+	maxSides     = 100
+	maxKeepRolls = 100000
+)
+
+// Term is a single signed part of an expression: either Count dice with
+// Sides faces, optionally keeping only the Keep highest (or lowest), or a
+// flat modifier of Count when Sides is 0.
+type Term struct {
+	Count    int
+	Sides    int
+	Keep     int
+	Lowest   bool
+	Negative bool
+}
+
+// Expr is a sum of terms.
+type Expr struct {
+	Terms []Term
+}
+
+// Rand is the source of randomness for Roll; *rand.Rand satisfies it.
+type Rand interface {
+	Intn(n int) int
+}
+
+// Parse reads an expression such as "18d10+36", "2d6 + 1d8 - 1", "d20",
+// "d%" or "4d6kh3".  Keep suffixes are "kh" or "k" for the highest dice and
+// "kl" for the lowest.
+func Parse(s string) (Expr, error) {
+	src := strings.ToLower(strings.TrimSpace(s))
+	if src == "" {
+		return Expr{}, fmt.Errorf("dice: empty expression")
+	}
+
+	var expr Expr
+	for i := 0; i < len(src); {
+		negative := false
+		if src[i] == '+' || src[i] == '-' {
+			negative = src[i] == '-'
+			i++
+		} else if i > 0 {
+			return Expr{}, fmt.Errorf("dice: expected + or - at %d in %q", i, s)
+		}
+		for i < len(src) && src[i] == ' ' {
+			i++
+		}
+
+		term, n, err := parseTerm(src[i:])
+		if err != nil {
+			return Expr{}, fmt.Errorf("dice: %w in %q", err, s)
+		}
+		term.Negative = negative
+		expr.Terms = append(expr.Terms, term)
+		i += n
+		for i < len(src) && src[i] == ' ' {
+			i++
+		}
+	}
+	return expr, nil
+}
+
+// parseTerm reads one unsigned term from the start of s and returns it with
+// the number of bytes consumed.
+func parseTerm(s string) (Term, int, error) {
+	i := 0
+	count, n := number(s)
+	i += n
+	if i >= len(s) || s[i] != 'd' {
+		if n == 0 {
+			return Term{}, 0, fmt.Errorf("expected a number or dice")
+		}
+		return Term{Count: count}, i, nil
+	}
+	if n == 0 {
+		count = 1
+	}
+	i++
+
+	var sides int
+	if i < len(s) && s[i] == '%' {
+		sides = 100
+		i++
+	} else {
+		sides, n = number(s[i:])
+		if n == 0 {
+			return Term{}, 0, fmt.Errorf("expected the number of sides")
+		}
+		i += n
+	}
+
+	term := Term{Count: count, Sides: sides}
+	if i < len(s) && s[i] == 'k' {
+		i++
+		if i < len(s) && (s[i] == 'h' || s[i] == 'l') {
+			term.Lowest = s[i] == 'l'
+			i++
+		}
+		term.Keep, n = number(s[i:])
+		if n == 0 {
+			return Term{}, 0, fmt.Errorf("expected the number of dice to keep")
+		}
+		i += n
+	}
+
+	switch {
+	case term.Count < 1 || term.Count > maxDice:
+		return Term{}, 0, fmt.Errorf("dice count %d is not between 1 and %d", term.Count, maxDice)
+	case term.Sides < 1 || term.Sides > maxSides:
+		return Term{}, 0, fmt.Errorf("die size %d is not between 1 and %d", term.Sides, maxSides)
+	case term.Keep < 0 || term.Keep > term.Count:
+		return Term{}, 0, fmt.Errorf("cannot keep %d of %d dice", term.Keep, term.Count)
+	case term.Keep == term.Count:
+		term.Keep, term.Lowest = 0, false
+	case term.Keep > 0 && multisets(term.Count, term.Sides) > maxKeepRolls:
+		return Term{}, 0, fmt.Errorf("too many dice to keep from %dd%d", term.Count, term.Sides)
+	}
+	return term, i, nil
+}
+
+func number(s string) (int, int) {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	v, err := strconv.Atoi(s[:n])
+	if err != nil {
+		return math.MaxInt, n
+	}
+	return v, n
+}
+
+// multisets counts the distinct sorted rolls of count dice with the given
+// sides, which is how many outcomes a keep term has to visit.
+func multisets(count, sides int) float64 {
+	result := 1.0
+	for i := 1; i <= count; i++ {
+		result = result * float64(sides-1+i) / float64(i)
+	}
+	return result
+}
+
+func (e Expr) String() string {
+	var b strings.Builder
+	for i, t := range e.Terms {
+		switch {
+		case t.Negative:
+			b.WriteString("-")
+		case i > 0:
+			b.WriteString("+")
+		}
+		b.WriteString(t.String())
+	}
+	return b.String()
+}
+
+// String formats the term without its sign.
+func (t Term) String() string {
+	if t.Sides == 0 {
+		return strconv.Itoa(t.Count)
+	}
+	s := fmt.Sprintf("%dd%d", t.Count, t.Sides)
+	if t.Keep > 0 {
+		if t.Lowest {
+			s += fmt.Sprintf("kl%d", t.Keep)
+		} else {
+			s += fmt.Sprintf("kh%d", t.Keep)
+		}
+	}
+	return s
+}
+
+func (e Expr) Min() int {
+	total := 0
+	for _, t := range e.Terms {
+		if t.Negative {
+			total -= t.max()
+		} else {
+			total += t.min()
+		}
+	}
+	return total
+}
+
+func (e Expr) Max() int {
+	total := 0
+	for _, t := range e.Terms {
+		if t.Negative {
+			total -= t.min()
+		} else {
+			total += t.max()
+		}
+	}
+	return total
+}
+
+// Average returns the exact expected value.  Monster and class hit points
+// use it rounded down.
+func (e Expr) Average() float64 {
+	total := 0.0
+	for _, t := range e.Terms {
+		avg := t.average()
+		if t.Negative {
+			avg = -avg
+		}
+		total += avg
+	}
+	return total
+}
+
+func (t Term) kept() int {
+	if t.Keep > 0 {
+		return t.Keep
+	}
+	return t.Count
+}
+
+func (t Term) min() int {
+	if t.Sides == 0 {
+		return t.Count
+	}
+	return t.kept()
+}
+
+func (t Term) max() int {
+	if t.Sides == 0 {
+		return t.Count
+	}
+	return t.kept() * t.Sides
+}
+
+func (t Term) average() float64 {
+	switch {
+	case t.Sides == 0:
+		return float64(t.Count)
+	case t.Keep == 0:
+		return float64(t.Count) * float64(t.Sides+1) / 2
+	}
+	avg := 0.0
+	for total, p := range t.distribution() {
+		avg += float64(total) * p
+	}
+	return avg
+}
+
+// Distribution maps every possible total to its probability.
+func (e Expr) Distribution() map[int]float64 {
+	dist := map[int]float64{0: 1}
+	for _, t := range e.Terms {
+		termDist := t.distribution()
+		if t.Negative {
+			negated := make(map[int]float64, len(termDist))
+			for total, p := range termDist {
+				negated[-total] = p
+			}
+			termDist = negated
+		}
+		dist = convolve(dist, termDist)
+	}
+	return dist
+}
+
+func convolve(a, b map[int]float64) map[int]float64 {
+	result := make(map[int]float64, len(a)+len(b))
+	for x, px := range a {
+		for y, py := range b {
+			result[x+y] += px * py
+		}
+	}
+	return result
+}
+
+func (t Term) distribution() map[int]float64 {
+	if t.Sides == 0 {
+		return map[int]float64{t.Count: 1}
+	}
+
+	die := make(map[int]float64, t.Sides)
+	for face := 1; face <= t.Sides; face++ {
+		die[face] = 1 / float64(t.Sides)
+	}
+	if t.Keep == 0 {
+		dist := map[int]float64{0: 1}
+		for i := 0; i < t.Count; i++ {
+			dist = convolve(dist, die)
+		}
+		return dist
+	}
+
+	dist := map[int]float64{}
+	t.keepOutcomes(t.Count, t.Keep, 0, 1, 0, dist)
+	norm := math.Pow(float64(t.Sides), float64(t.Count))
+	for total := range dist {
+		dist[total] /= norm
+	}
+	return dist
+}
+
+// keepOutcomes visits every sorted roll by deciding how many dice show
+// each face, from the faces kept first (the highest, unless keeping the
+// lowest) onwards.  ways is the number of unsorted rolls the choices so
+// far stand for.
+func (t Term) keepOutcomes(remaining, keep, step int, ways float64, sum int, dist map[int]float64) {
+	if remaining == 0 || step == t.Sides {
+		if remaining == 0 {
+			dist[sum] += ways
+		}
+		return
+	}
+
+	face := t.Sides - step
+	if t.Lowest {
+		face = step + 1
+	}
+	choose := 1.0
+	for n := 0; n <= remaining; n++ {
+		if n > 0 {
+			choose = choose * float64(remaining-n+1) / float64(n)
+		}
+		kept := min(n, keep)
+		t.keepOutcomes(remaining-n, keep-kept, step+1, ways*choose, sum+kept*face, dist)
+	}
+}
+
+// Roll rolls the expression, drawing every die from rng.
+func (e Expr) Roll(rng Rand) int {
+	total := 0
+	for _, t := range e.Terms {
+		v := t.roll(rng)
+		if t.Negative {
+			v = -v
+		}
+		total += v
+	}
+	return total
+}
+
+func (t Term) roll(rng Rand) int {
+	if t.Sides == 0 {
+		return t.Count
+	}
+	rolls := make([]int, t.Count)
+	for i := range rolls {
+		rolls[i] = rng.Intn(t.Sides) + 1
+	}
+	if t.Keep > 0 {
+		if t.Lowest {
+			sort.Ints(rolls)
+		} else {
+			sort.Sort(sort.Reverse(sort.IntSlice(rolls)))
+		}
+		rolls = rolls[:t.Keep]
+	}
+	total := 0
+	for _, r := range rolls {
+		total += r
+	}
+	return total
+}
@@ -0,0 +1,133 @@
+package dice
+
+import (
+	"database/sql"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+		min  int
+		max  int
+		avg  float64
+	}{
+		{"18d10+36", "18d10+36", 54, 216, 135},
+		{"1d12", "1d12", 1, 12, 6.5},
+		{"2d6 + 5", "2d6+5", 7, 17, 12},
+		{"d20", "1d20", 1, 20, 10.5},
+		{"D%", "1d100", 1, 100, 50.5},
+		{"1d8 + 2d6 - 1", "1d8+2d6-1", 2, 19, 10.5},
+		{"3", "3", 3, 3, 3},
+		{"4d6kh3", "4d6kh3", 3, 18, 15869.0 / 1296},
+		{"2d20k1", "2d20kh1", 1, 20, 13.825},
+		{"2d20kl1", "2d20kl1", 1, 20, 7.175},
+		{"2d6kh2", "2d6", 2, 12, 7},
+		{"-1d4", "-1d4", -4, -1, -2.5},
+	}
+	for _, c := range cases {
+		expr, err := Parse(c.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", c.in, err)
+			continue
+		}
+		if got := expr.String(); got != c.want {
+			t.Errorf("Parse(%q) = %q, want %q", c.in, got, c.want)
+		}
+		if expr.Min() != c.min || expr.Max() != c.max {
+			t.Errorf("%q bounds = %d..%d, want %d..%d", c.in, expr.Min(), expr.Max(), c.min, c.max)
+		}
+		if math.Abs(expr.Average()-c.avg) > 1e-9 {
+			t.Errorf("%q average = %v, want %v", c.in, expr.Average(), c.avg)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, in := range []string{"", "d", "2d", "1d6+", "2d6 5", "3d6k4", "1d6kh", "0d6", "1d0", "1d6x"} {
+		if expr, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %q, want an error", in, expr)
+		}
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	for _, in := range []string{"2d6+1", "4d6kh3", "2d20kl1", "1d8-1d4"} {
+		expr, err := Parse(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dist := expr.Distribution()
+		total, mean := 0.0, 0.0
+		for v, p := range dist {
+			if v < expr.Min() || v > expr.Max() {
+				t.Errorf("%q: %d is outside %d..%d", in, v, expr.Min(), expr.Max())
+			}
+			total += p
+			mean += float64(v) * p
+		}
+		if math.Abs(total-1) > 1e-9 {
+			t.Errorf("%q: probabilities sum to %v", in, total)
+		}
+		if math.Abs(mean-expr.Average()) > 1e-9 {
+			t.Errorf("%q: distribution mean %v, average %v", in, mean, expr.Average())
+		}
+	}
+
+	expr, _ := Parse("2d6")
+	if p := expr.Distribution()[7]; math.Abs(p-6.0/36) > 1e-9 {
+		t.Errorf("P(2d6 = 7) = %v, want 1/6", p)
+	}
+	expr, _ = Parse("4d6kh3")
+	if p := expr.Distribution()[18]; math.Abs(p-21.0/1296) > 1e-9 {
+		t.Errorf("P(4d6kh3 = 18) = %v, want 21/1296", p)
+	}
+}
+
+// fixedRand returns its rolls in order, as zero-based die faces.
+type fixedRand []int
+
+func (r *fixedRand) Intn(n int) int {
+	v := (*r)[0]
+	*r = (*r)[1:]
+	return v
+}
+
+func TestRoll(t *testing.T) {
+	expr, _ := Parse("4d6kh3 + 2")
+	rolls := fixedRand{0, 5, 2, 3}
+	if got := expr.Roll(&rolls); got != 6+3+4+2 {
+		t.Errorf("Roll = %d, want 15", got)
+	}
+
+	expr, _ = Parse("18d10+36")
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		if v := expr.Roll(rng); v < expr.Min() || v > expr.Max() {
+			t.Fatalf("Roll = %d, outside %d..%d", v, expr.Min(), expr.Max())
+		}
+	}
+}
+
+func TestSQLiteFuncs(t *testing.T) {
+	db, err := sql.Open(DriverName, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var avg float64
+	var max, min int
+	var bad sql.NullFloat64
+	err = db.QueryRow(`SELECT dice_avg('18d10+36'), dice_max('2d6 + 5'), dice_min('1d8-1'), dice_avg('Other')`).
+		Scan(&avg, &max, &min, &bad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if avg != 135 || max != 17 || min != 0 || bad.Valid {
+		t.Errorf("got %v, %d, %d, %v", avg, max, min, bad)
+	}
+}
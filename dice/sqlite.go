@@ -0,0 +1,56 @@
+package dice
+
+import (
+	"database/sql"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// DriverName is a go-sqlite3 driver whose connections also provide the
+// SQL functions dice_min, dice_max and dice_avg, e.g.
+//
+//	SELECT slug, dice_avg(hit_dice) FROM mob_imports
+//
+// Each takes a dice expression and returns NULL for one that does not
+// parse, so a query over imported text is not aborted by a stray value.
+const DriverName = "sqlite3_dice"
+
+func init() {
+	sql.Register(DriverName, &sqlite3.SQLiteDriver{ConnectHook: RegisterFuncs})
+}
+
+// RegisterFuncs adds the dice functions to a connection opened through
+// another driver's ConnectHook.
+func RegisterFuncs(conn *sqlite3.SQLiteConn) error {
+	funcs := map[string]func(Expr) any{
+		"dice_min": func(e Expr) any { return e.Min() },
+		"dice_max": func(e Expr) any { return e.Max() },
+		"dice_avg": func(e Expr) any { return e.Average() },
+	}
+	for name, f := range funcs {
+		err := conn.RegisterFunc(name, sqlFunc(f), true)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sqlFunc(f func(Expr) any) func(any) any {
+	return func(arg any) any {
+		var s string
+		switch v := arg.(type) {
+		case string:
+			s = v
+		case []byte:
+			s = string(v)
+		default:
+			return nil
+		}
+		expr, err := Parse(s)
+		if err != nil {
+			return nil
+		}
+		return f(expr)
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/dice"
+	"open5e_importer/importers/classes"
+	"open5e_importer/importers/conditions"
+	"open5e_importer/importers/documents"
+	"open5e_importer/importers/monsters"
+	"open5e_importer/importers/planes"
+	"open5e_importer/importers/races"
+	"open5e_importer/importers/sections"
+	"open5e_importer/importers/spelllists"
+	"open5e_importer/importers/spells"
+	"open5e_importer/open5e"
+)
+
+type importFunc func(db *sqlx.DB, client *open5e.Client, version open5e.Version) (int, error)
+
+type step struct {
+	name      string
+	dependsOn []string
+	run       importFunc
+}
+
+// every resource with the resources it references.  a step may only
+// depend on steps listed before it, which keeps the graph acyclic.
+var steps = []step{
+	{"documents", nil, documents.Import},
+	{"conditions", []string{"documents"}, conditions.Import},
+	{"planes", []string{"documents"}, planes.Import},
+	{"sections", []string{"documents"}, sections.Import},
+	{"spells", []string{"documents"}, spells.Import},
+	{"races", []string{"documents"}, races.Import},
+	{"classes", []string{"documents"}, classes.Import},
+	{"spelllists", []string{"documents", "classes", "spells"}, spelllists.Import},
+	{"monsters", []string{"documents", "spells"}, monsters.Import},
+	{"monster_derived", []string{"monsters"}, deriveMonsters},
+}
+
+// deriveMonsters works from what the monsters step wrote rather than the
+// API.
+func deriveMonsters(db *sqlx.DB, _ *open5e.Client, _ open5e.Version) (int, error) {
+	return monsters.Derive(db)
+}
+
+type result struct {
+	name     string
+	count    int
+	duration time.Duration
+	err      error
+	// the resource isn't served by the version of the API imported from,
+	// which is reported but isn't a failure
+	skipped bool
+}
+
+func main() {
+	os.Exit(run())
+}
+
+// run imports the resources and returns the exit status, so that main
+// only exits once the database and client are closed.
+func run() int {
+	api := flag.String("api", string(open5e.V1), "Open5e API version to import from (v1 or v2)")
+	dbPath := flag.String("db", "../mud/sql_database/open5e_imports.db", "SQLite database to import into")
+	rate := flag.Float64("rate", 5, "maximum requests per second to the Open5e API, shared by all resources")
+	only := flag.String("only", "", "comma separated resources to import, instead of all of them")
+	flag.Parse()
+
+	version, err := open5e.ParseVersion(*api)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	selected, err := selectSteps(steps, *only)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// the dice driver lets later steps' queries use dice_avg and friends
+	db, err := sqlx.Open(dice.DriverName, *dbPath+"?_foreign_keys=on&_busy_timeout=5000")
+	if err != nil {
+		log.Fatalf("Failed to open SQLite database: %v", err)
+	} else {
+		err := db.Ping()
+		if err != nil {
+			log.Fatalf("Failed to ping database: %v", err)
+		}
+		fmt.Println("Database opened successfully")
+	}
+	defer db.Close()
+	// resources are fetched concurrently, but sqlite only takes one writer
+	db.SetMaxOpenConns(1)
+
+	client := open5e.NewClient(*rate)
+	defer client.Close()
+	start := time.Now()
+	results := runSteps(selected, func(s step) (int, error) {
+		return s.run(db, client, version)
+	})
+
+	failed := writeSummary(os.Stdout, results, time.Since(start))
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+// selectSteps returns the steps named in only, or every step if only is
+// empty.  dependencies which aren't selected are assumed to have been
+// imported by an earlier run.
+func selectSteps(steps []step, only string) ([]step, error) {
+	if only == "" {
+		return steps, nil
+	}
+
+	wanted := map[string]bool{}
+	for _, name := range strings.Split(only, ",") {
+		wanted[strings.TrimSpace(name)] = true
+	}
+
+	var selected []step
+	for _, s := range steps {
+		if wanted[s.name] {
+			selected = append(selected, s)
+			delete(wanted, s.name)
+		}
+	}
+	for name := range wanted {
+		return nil, fmt.Errorf("unknown resource %q", name)
+	}
+	return selected, nil
+}
+
+// runSteps starts every step once the steps it depends on have finished,
+// so independent steps run concurrently.  a step whose dependency failed
+// is skipped.  a step whose resource isn't available in the version of
+// the API is skipped too, but its dependents still run.
+func runSteps(steps []step, run func(step) (int, error)) []result {
+	results := make([]result, len(steps))
+	index := map[string]int{}
+	done := make([]chan struct{}, len(steps))
+	for i, s := range steps {
+		index[s.name] = i
+		done[i] = make(chan struct{})
+	}
+
+	var wg sync.WaitGroup
+	for i, s := range steps {
+		wg.Add(1)
+		go func(i int, s step) {
+			defer wg.Done()
+			defer close(done[i])
+
+			for _, dependency := range s.dependsOn {
+				d, ok := index[dependency]
+				if !ok {
+					continue
+				}
+				<-done[d]
+				if results[d].err != nil && !results[d].skipped {
+					results[i] = result{name: s.name, err: fmt.Errorf("skipped, %s failed", dependency)}
+					return
+				}
+			}
+
+			start := time.Now()
+			count, err := run(s)
+			results[i] = result{name: s.name, count: count, duration: time.Since(start), err: err,
+				skipped: errors.Is(err, open5e.ErrUnavailable)}
+		}(i, s)
+	}
+	wg.Wait()
+	return results
+}
+
+// writeSummary prints one line per resource and reports whether any of
+// them failed.
+func writeSummary(out io.Writer, results []result, elapsed time.Duration) bool {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tIMPORTED\tDURATION\tSTATUS")
+
+	failed := false
+	total := 0
+	for _, r := range results {
+		status := "ok"
+		if r.skipped {
+			status = "skipped, " + r.err.Error()
+		} else if r.err != nil {
+			status = r.err.Error()
+			failed = true
+		}
+		total += r.count
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", r.name, r.count, r.duration.Round(time.Millisecond), status)
+	}
+	fmt.Fprintf(w, "total\t%d\t%s\t\n", total, elapsed.Round(time.Millisecond))
+	w.Flush()
+	return failed
+}
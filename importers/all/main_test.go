@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"open5e_importer/open5e"
+)
+
+func TestStepsDependOnEarlierSteps(t *testing.T) {
+	seen := map[string]bool{}
+	for _, s := range steps {
+		for _, dependency := range s.dependsOn {
+			if !seen[dependency] {
+				t.Errorf("%s depends on %s, which is not listed before it", s.name, dependency)
+			}
+		}
+		seen[s.name] = true
+	}
+}
+
+func TestRunStepsOrder(t *testing.T) {
+	var mu sync.Mutex
+	finished := map[string]bool{}
+	results := runSteps(steps, func(s step) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, dependency := range s.dependsOn {
+			if !finished[dependency] {
+				t.Errorf("%s started before %s finished", s.name, dependency)
+			}
+		}
+		finished[s.name] = true
+		return 1, nil
+	})
+
+	if len(results) != len(steps) {
+		t.Fatalf("expected %d results, got %d", len(steps), len(results))
+	}
+	for _, r := range results {
+		if r.err != nil || r.count != 1 {
+			t.Errorf("unexpected result %+v", r)
+		}
+	}
+}
+
+func TestRunStepsSkipsDependentsOfFailures(t *testing.T) {
+	results := runSteps(steps, func(s step) (int, error) {
+		if s.name == "spells" {
+			return 0, errors.New("boom")
+		}
+		return 1, nil
+	})
+
+	status := map[string]error{}
+	for _, r := range results {
+		status[r.name] = r.err
+	}
+	for _, name := range []string{"spells", "spelllists", "monsters"} {
+		if status[name] == nil {
+			t.Errorf("expected %s to fail", name)
+		}
+	}
+	for _, name := range []string{"documents", "classes", "races"} {
+		if status[name] != nil {
+			t.Errorf("expected %s to succeed, got %v", name, status[name])
+		}
+	}
+}
+
+func TestRunStepsSkipsUnavailable(t *testing.T) {
+	results := runSteps(steps, func(s step) (int, error) {
+		if s.name == "spells" {
+			return 0, fmt.Errorf("%w (v2)", open5e.ErrUnavailable)
+		}
+		return 1, nil
+	})
+
+	var out bytes.Buffer
+	if writeSummary(&out, results, 0) {
+		t.Errorf("an unavailable resource failed the import:\n%s", out.String())
+	}
+	for _, r := range results {
+		if r.name == "spells" && !r.skipped {
+			t.Errorf("expected spells to be skipped, got %+v", r)
+		}
+		// spelllists and monsters depend on spells, but still run
+		if r.name != "spells" && (r.err != nil || r.count != 1) {
+			t.Errorf("unexpected result %+v", r)
+		}
+	}
+	if !strings.Contains(out.String(), "skipped") {
+		t.Errorf("summary doesn't report spells as skipped:\n%s", out.String())
+	}
+}
+
+func TestSelectSteps(t *testing.T) {
+	selected, err := selectSteps(steps, "monsters, documents")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selected) != 2 || selected[0].name != "documents" || selected[1].name != "monsters" {
+		t.Errorf("unexpected selection %v", selected)
+	}
+
+	// spells isn't selected, so monsters only waits on documents
+	results := runSteps(selected, func(s step) (int, error) { return 0, nil })
+	if len(results) != 2 || results[1].err != nil {
+		t.Errorf("unexpected results %+v", results)
+	}
+
+	if _, err := selectSteps(steps, "dragons"); err == nil {
+		t.Error("expected an error for an unknown resource")
+	}
+}
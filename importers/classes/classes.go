@@ -0,0 +1,249 @@
+package classes
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"open5e_importer/open5e"
+)
+
+// Import fetches every class from the Open5e API and writes them to db,
+// returning the number of classes imported.
+func Import(db *sqlx.DB, client *open5e.Client, version open5e.Version) (int, error) {
+	return open5e.Import(client, classResource, version, func(page []ClassImport) error {
+		return writeClassesToDB(db, page)
+	})
+}
+
+type ClassImport struct {
+	Name                      string        `json:"name" db:"name"`
+	Slug                      string        `json:"slug" db:"slug"`
+	Description               string        `json:"desc" db:"description"`
+	HitDice                   string        `json:"hit_dice" db:"hit_dice"`
+	HpAtFirstLevel            string        `json:"hp_at_1st_level" db:"hp_at_first_level"`
+	HpAtHigherLevels          string        `json:"hp_at_higher_levels" db:"hp_at_higher_levels"`
+	ProficienciesArmor        string        `json:"prof_armor" db:"proficiencies_armor"`
+	ProficienciesWeapons      string        `json:"prof_weapons" db:"proficiencies_weapons"`
+	ProficienciesTools        string        `json:"prof_tools" db:"proficiencies_tools"`
+	ProficienciesSavingThrows string        `json:"prof_saving_throws" db:"proficiencies_saving_throws"`
+	ProficienciesSkills       string        `json:"prof_skills" db:"proficiencies_skills"`
+	Equipment                 string        `json:"equipment" db:"equipment"`
+	Table                     string        `json:"table" db:"class_table"`
+	SpellcastingAbility       string        `json:"spellcasting_ability" db:"spellcasting_ability"`
+	SubtypesName              string        `json:"subtypes_name" db:"subtypes_name"`
+	Archetypes                []interface{} `json:"archetypes" db:"archetypes"`
+	DocumentSlug              string        `json:"document__slug" db:"document_slug"`
+
+	// v2 serves subclasses from the classes endpoint, and gives the slug
+	// of their class here
+	SubclassOf string `json:"-" db:"-"`
+}
+
+// json keys of v1 results whose ClassImport field isn't named after them
+var classAliases = map[string]string{
+	"desc":               "Description",
+	"hp_at_1st_level":    "HpAtFirstLevel",
+	"prof_armor":         "ProficienciesArmor",
+	"prof_weapons":       "ProficienciesWeapons",
+	"prof_tools":         "ProficienciesTools",
+	"prof_saving_throws": "ProficienciesSavingThrows",
+	"prof_skills":        "ProficienciesSkills",
+	"class_table":        "Table",
+}
+
+var classResource = open5e.Resource[ClassImport]{
+	V1Path:   "classes",
+	DecodeV1: open5e.DecodeV1[ClassImport](classAliases),
+	V2Path:   "classes",
+	DecodeV2: decodeClassV2,
+}
+
+func writeClassesToDB(db *sqlx.DB, classes []ClassImport) error {
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS class_imports (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT,
+			slug TEXT,
+			description TEXT,
+			hit_dice TEXT,
+			hp_at_first_level TEXT,
+			hp_at_higher_levels TEXT,
+			proficiencies_armor TEXT,
+			proficiencies_weapons TEXT,
+			proficiencies_tools TEXT,
+			proficiencies_saving_throws TEXT,
+			proficiencies_skills TEXT,
+			equipment TEXT,
+			class_table TEXT,
+			spellcasting_ability TEXT,
+			subtypes_name TEXT,
+			archetypes TEXT,
+			document_slug TEXT REFERENCES documents(slug),
+			hp_first_level INTEGER,
+			hit_die INTEGER,
+			hp_per_level INTEGER
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create class_imports: %w", err)
+	}
+	for _, create := range []func(*sqlx.DB) error{
+		createClassLevelTables,
+		createClassFeatureTables,
+		createSubclassTables,
+		createClassProficiencyTable,
+		createClassEquipmentTable,
+	} {
+		err = create(db)
+		if err != nil {
+			return err
+		}
+	}
+
+	items, err := loadEquipmentIndex(db)
+	if err != nil {
+		return err
+	}
+
+	for _, class := range classes {
+		// a class and its child rows are replaced together, so a re-import
+		// never leaves rows of the previous import behind.  v2 serves
+		// subclasses as classes of their own, which are replaced alone.
+		tx, err := db.Beginx()
+		if err != nil {
+			return err
+		}
+		if class.SubclassOf != "" {
+			err = writeSubclassToDB(tx, Subclass{
+				ClassSlug:    class.SubclassOf,
+				Slug:         class.Slug,
+				Name:         class.Name,
+				DocumentSlug: class.DocumentSlug,
+				Description:  class.Description,
+			})
+		} else {
+			err = writeClassToDB(tx, class, items)
+		}
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("%s: %w", class.Slug, err)
+		}
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writes the class to class_imports and its other tables, with the
+// subclasses it lists, after deleting what an earlier import wrote for
+// it.
+func writeClassToDB(tx *sqlx.Tx, class ClassImport, items equipmentIndex) error {
+	err := deleteClassFromDB(tx, class.Slug)
+	if err != nil {
+		return err
+	}
+	// v1 lists every archetype on the class, so the ones it no longer
+	// lists go too.  a v2 class has no list, and its subclasses are
+	// replaced one at a time.
+	if class.Archetypes != nil {
+		err = deleteClassSubclassesFromDB(tx, class.Slug)
+		if err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO class_imports (
+			name, slug, description, hit_dice, hp_at_first_level,
+			hp_at_higher_levels, proficiencies_armor, proficiencies_weapons,
+			proficiencies_tools, proficiencies_saving_throws, proficiencies_skills,
+			equipment, class_table, spellcasting_ability, subtypes_name, archetypes,
+			document_slug, hp_first_level, hit_die, hp_per_level
+		)
+		VALUES
+		(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	archetypesJson, err := json.Marshal(class.Archetypes)
+	if err != nil {
+		return err
+	}
+
+	// the hit point columns are left NULL when they aren't understood
+	var hpFirstLevel, hitDie, hpPerLevel interface{}
+	hp, err := ParseHitPoints(class)
+	if err != nil {
+		fmt.Printf("Hit points of %s not understood: %v\n", class.Slug, err)
+	} else {
+		hpFirstLevel, hitDie, hpPerLevel = hp.FirstLevel, hp.HitDie, hp.PerLevel
+	}
+
+	_, err = tx.Exec(query, class.Name, class.Slug, class.Description, class.HitDice,
+		class.HpAtFirstLevel, class.HpAtHigherLevels, class.ProficienciesArmor,
+		class.ProficienciesWeapons, class.ProficienciesTools, class.ProficienciesSavingThrows,
+		class.ProficienciesSkills, class.Equipment, class.Table, class.SpellcastingAbility,
+		class.SubtypesName, archetypesJson, class.DocumentSlug, hpFirstLevel, hitDie, hpPerLevel)
+	if err != nil {
+		return err
+	}
+
+	for _, write := range []func(sqlx.Execer, ClassImport) error{
+		writeClassLevelsToDB,
+		writeClassFeaturesToDB,
+		writeClassProficienciesToDB,
+	} {
+		err = write(tx, class)
+		if err != nil {
+			return err
+		}
+	}
+	err = writeClassEquipmentToDB(tx, class, items)
+	if err != nil {
+		return err
+	}
+	for _, subclass := range classSubclasses(class) {
+		err = writeSubclassToDB(tx, subclass)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deletes the class and every row written for it from the other class
+// tables, so it can be written again.  its subclasses are deleted apart
+// from it, since v2 serves them apart from the class.
+func deleteClassFromDB(db sqlx.Execer, classSlug string) error {
+	for _, query := range []string{
+		`DELETE FROM class_levels WHERE class_slug = ?`,
+		`DELETE FROM class_level_features WHERE class_slug = ?`,
+		`DELETE FROM class_level_values WHERE class_slug = ?`,
+		`DELETE FROM class_features WHERE class_slug = ?`,
+		`DELETE FROM class_feature_mismatches WHERE class_slug = ?`,
+		`DELETE FROM class_proficiencies WHERE class_slug = ?`,
+		`DELETE FROM class_equipment WHERE class_slug = ?`,
+		`DELETE FROM class_imports WHERE slug = ?`,
+	} {
+		_, err := db.Exec(query, classSlug)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func convertJsonToClassImports(jsonData []byte, version open5e.Version) ([]ClassImport, string) {
+	classes, nextUrl, err := open5e.DecodePage(classResource, version, jsonData)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if nextUrl == "" {
+		return classes, "DONE"
+	}
+	return classes, nextUrl
+}
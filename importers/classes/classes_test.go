@@ -0,0 +1,165 @@
+package classes
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/open5e"
+)
+
+func TestImportClasses(t *testing.T) {
+	err := os.Remove("../../sql_database/classes_test.db")
+	if err != nil {
+		if os.IsNotExist(err) {
+			// don't worry about it!
+		} else {
+			log.Fatal(err)
+		}
+	}
+	db, err := sqlx.Open("sqlite3", "../../sql_database/classes_test.db")
+	if err != nil {
+		log.Fatalf("Failed to open sqlite db: %v", err)
+	}
+	if err != nil {
+		if os.IsNotExist(err) {
+			// don't worry about it!
+		} else {
+			t.Fatal(err)
+		}
+	}
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	classes, _ := convertJsonToClassImports(data, open5e.V1)
+	if err := writeClassesToDB(db, classes); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDecodeClassesV2(t *testing.T) {
+	v1Data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2Data, err := ioutil.ReadFile("./test_data/testdata_v2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1Classes, _ := convertJsonToClassImports(v1Data, open5e.V1)
+	v2Results, next := convertJsonToClassImports(v2Data, open5e.V2)
+	if next != "DONE" {
+		t.Errorf("unexpected next url %q", next)
+	}
+	// subclasses are decoded alongside their class
+	var v2Classes []ClassImport
+	var subclasses []string
+	for _, class := range v2Results {
+		if class.SubclassOf != "" {
+			subclasses = append(subclasses, class.SubclassOf+"/"+class.Slug)
+			continue
+		}
+		v2Classes = append(v2Classes, class)
+	}
+	if len(v2Classes) != 2 {
+		t.Fatalf("expected 2 classes, got %d", len(v2Classes))
+	}
+	wantSubclasses := "srd_barbarian/srd_path-of-the-berserker srd_bard/srd_college-of-lore"
+	if got := strings.Join(subclasses, " "); got != wantSubclasses {
+		t.Errorf("subclasses = %q, want %q", got, wantSubclasses)
+	}
+
+	for i, v2 := range v2Classes {
+		v1 := v1Classes[i]
+		if v2.Slug != "srd_"+v1.Slug || v2.Name != v1.Name {
+			t.Errorf("unexpected class %q (%s), expected %s", v2.Slug, v2.Name, v1.Name)
+		}
+		if v2.HitDice != v1.HitDice || v2.HpAtFirstLevel != v1.HpAtFirstLevel || v2.HpAtHigherLevels != v1.HpAtHigherLevels {
+			t.Errorf("%s: hit points differ between v1 and v2", v1.Slug)
+		}
+		if v2.ProficienciesSavingThrows != v1.ProficienciesSavingThrows || v2.ProficienciesSkills != v1.ProficienciesSkills {
+			t.Errorf("%s: proficiencies differ between v1 and v2", v1.Slug)
+		}
+		if v2.Equipment != v1.Equipment {
+			t.Errorf("%s: equipment differs between v1 and v2", v1.Slug)
+		}
+		if !strings.HasPrefix(v2.Description, "### ") {
+			t.Errorf("%s: expected a markdown description, got %q", v1.Slug, v2.Description)
+		}
+	}
+}
+
+func TestReimportClasses(t *testing.T) {
+	err := os.Remove("../../sql_database/classes_reimport_test.db")
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	db, err := sqlx.Open("sqlite3", "../../sql_database/classes_reimport_test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// v1 classes list their subclasses, v2 serves them apart
+	var classes []ClassImport
+	for _, fixture := range []struct {
+		file    string
+		version open5e.Version
+	}{{"./test_data/testdata.json", open5e.V1}, {"./test_data/testdata_v2.json", open5e.V2}} {
+		data, err := ioutil.ReadFile(fixture.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page, _ := convertJsonToClassImports(data, fixture.version)
+		classes = append(classes, page...)
+	}
+
+	tables := []string{"class_imports", "class_levels", "class_level_features", "class_level_values",
+		"class_features", "class_feature_mismatches", "class_proficiencies", "class_equipment",
+		"subclass_imports", "subclass_features"}
+	counts := func() []int {
+		var counts []int
+		for _, table := range tables {
+			var count int
+			if err := db.Get(&count, "SELECT COUNT(*) FROM "+table); err != nil {
+				t.Fatal(err)
+			}
+			counts = append(counts, count)
+		}
+		return counts
+	}
+	if err := writeClassesToDB(db, classes); err != nil {
+		t.Fatal(err)
+	}
+	first := counts()
+	if err := writeClassesToDB(db, classes); err != nil {
+		t.Fatal(err)
+	}
+	if second := counts(); !reflect.DeepEqual(first, second) {
+		t.Errorf("re-import changed the row counts of %v from %v to %v", tables, first, second)
+	}
+	for i, count := range first {
+		if count == 0 {
+			t.Errorf("nothing written to %s", tables[i])
+		}
+	}
+
+	// an archetype v1 no longer lists is deleted with the class's others
+	class := classes[0]
+	class.Archetypes = class.Archetypes[:1]
+	if err := writeClassesToDB(db, []ClassImport{class}); err != nil {
+		t.Fatal(err)
+	}
+	var subclasses int
+	if err := db.Get(&subclasses, `SELECT COUNT(*) FROM subclass_imports WHERE class_slug = ?`, class.Slug); err != nil {
+		t.Fatal(err)
+	}
+	if subclasses != 1 {
+		t.Errorf("%s has %d subclasses, want 1", class.Slug, subclasses)
+	}
+}
@@ -0,0 +1,229 @@
+package classes
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// EquipmentChoice is a bullet of a class's starting equipment, e.g. "(*a*)
+// a greataxe or (*b*) any martial melee weapon".  one of its Alternatives
+// is taken, and a bullet without any choice has just the one.
+type EquipmentChoice struct {
+	Alternatives [][]EquipmentItem
+}
+
+// EquipmentItem is an item of starting equipment.  when Category is set
+// Name is a kind of equipment, like "martial melee weapon" or "musical
+// instrument", and any one of that kind can be taken.  Slug is Name the
+// way Open5e slugs weapons, armor and gear, for matching them up once they
+// are imported.
+type EquipmentItem struct {
+	Quantity int32
+	Name     string
+	Slug     string
+	Kind     string // "weapon", "armor", "pack" or "gear"
+	Category bool
+	Note     string // e.g. "if proficient"
+}
+
+var knownArmorItems = []string{
+	"padded armor", "leather armor", "studded leather armor", "hide armor", "chain shirt",
+	"scale mail", "breastplate", "half plate", "ring mail", "chain mail", "splint armor",
+	"plate armor", "shield",
+}
+
+var (
+	equipLetter   = regexp.MustCompile(`\(\*[a-z]\*\)|\*\([a-z]\)\*|\([a-z]\)`)
+	equipSplit    = regexp.MustCompile(`\s*,\s*(?:and\s+)?|\s+and\s+`)
+	equipNote     = regexp.MustCompile(`\s*\(([^)]*)\)`)
+	equipQuantity = regexp.MustCompile(`^(?:(a|an|one|two|three|four|five|six|seven|eight|nine|ten|\d+) )?(.+)$`)
+	equipAny      = regexp.MustCompile(`^any (?:other )?`)
+	equipCategory = regexp.MustCompile(`^(?:simple|martial)(?: melee| ranged)? weapon$`)
+	equipOf       = regexp.MustCompile(`^(.+?) of (\d+ .+)$`)
+)
+
+// ParseEquipment reads a class's starting equipment, a line of text
+// followed by a markdown list with a choice per bullet.
+func ParseEquipment(equipment string) []EquipmentChoice {
+	var choices []EquipmentChoice
+	for _, line := range strings.Split(equipment, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "* ") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "* "))
+
+		var choice EquipmentChoice
+		for _, alternative := range equipLetter.Split(line, -1) {
+			alternative = strings.TrimSpace(alternative)
+			alternative = strings.TrimSuffix(strings.TrimSuffix(alternative, " or"), ",")
+			if alternative == "" {
+				continue
+			}
+			choice.Alternatives = append(choice.Alternatives, equipmentItems(alternative))
+		}
+		choices = append(choices, choice)
+	}
+	return choices
+}
+
+// equipmentItems splits an alternative into its items, e.g. "leather
+// armor, longbow, and 20 arrows".  "a quiver of 20 arrows" is two items.
+func equipmentItems(text string) []EquipmentItem {
+	var items []EquipmentItem
+	for _, part := range equipSplit.Split(text, -1) {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		if m := equipOf.FindStringSubmatch(part); m != nil {
+			items = append(items, equipmentItem(m[1]), equipmentItem(m[2]))
+			continue
+		}
+		items = append(items, equipmentItem(part))
+	}
+	return items
+}
+
+func equipmentItem(text string) EquipmentItem {
+	item := EquipmentItem{Quantity: 1, Kind: "gear"}
+	if m := equipNote.FindStringSubmatch(text); m != nil {
+		item.Note = m[1]
+		text = equipNote.ReplaceAllString(text, "")
+	}
+	if equipAny.MatchString(text) {
+		item.Category = true
+		text = equipAny.ReplaceAllString(text, "")
+	}
+	m := equipQuantity.FindStringSubmatch(text)
+	if m[1] != "" && m[1] != "a" && m[1] != "an" {
+		item.Quantity = countWord(m[1])
+	}
+	item.Name = m[2]
+	if item.Quantity > 1 {
+		item.Name = strings.TrimSuffix(item.Name, "s")
+	}
+
+	switch {
+	case equipCategory.MatchString(item.Name):
+		item.Category, item.Kind = true, "weapon"
+	case contains(knownWeapons, item.Name):
+		item.Kind = "weapon"
+	case contains(knownArmorItems, item.Name), strings.HasSuffix(item.Name, " shield"):
+		item.Kind = "armor"
+	case strings.HasSuffix(item.Name, " pack"):
+		item.Kind = "pack"
+	}
+	item.Slug = slugify(item.Name)
+	return item
+}
+
+func slugify(name string) string {
+	name = strings.NewReplacer("'", "", "’", "").Replace(strings.ToLower(name))
+	return strings.Trim(tableKey.ReplaceAllString(name, "-"), "-")
+}
+
+func createClassEquipmentTable(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS class_equipment (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			class_slug TEXT,
+			choice INTEGER,
+			alternative INTEGER,
+			position INTEGER,
+			quantity INTEGER,
+			name TEXT,
+			slug TEXT,
+			kind TEXT,
+			category BOOLEAN,
+			note TEXT,
+			imported BOOLEAN
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create class_equipment: %w", err)
+	}
+	return nil
+}
+
+// equipmentIndex has the slugs of the imported weapons and armor by kind,
+// "weapon" or "armor".  a kind which hasn't been imported isn't in it.
+type equipmentIndex map[string]map[string]bool
+
+// loadEquipmentIndex reads the slugs of weapon_imports and armor_imports,
+// leaving out either table when it doesn't exist.  nothing imports weapons
+// or armor yet; an importer for them has to run before the classes.
+func loadEquipmentIndex(db sqlx.Queryer) (equipmentIndex, error) {
+	index := equipmentIndex{}
+	for kind, table := range map[string]string{"weapon": "weapon_imports", "armor": "armor_imports"} {
+		var tables int
+		err := sqlx.Get(db, &tables, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table)
+		if err != nil {
+			return nil, err
+		}
+		if tables == 0 {
+			continue
+		}
+		var slugs []string
+		err = sqlx.Select(db, &slugs, `SELECT slug FROM `+table)
+		if err != nil {
+			return nil, err
+		}
+		index[kind] = map[string]bool{}
+		for _, slug := range slugs {
+			index[kind][slug] = true
+		}
+	}
+	return index, nil
+}
+
+// imported is whether the item is one of the imported weapons or armor.
+// known is false when that can't be told: for a category, for gear, and
+// for a kind that hasn't been imported.
+func (index equipmentIndex) imported(item EquipmentItem) (imported, known bool) {
+	slugs, ok := index[item.Kind]
+	if !ok || item.Category {
+		return false, false
+	}
+	return slugs[item.Slug], true
+}
+
+// writes a row to class_equipment for each item of the class's starting
+// equipment.  an item belongs to alternative of choice, both numbered from
+// 1, and a character takes every item of one alternative of each choice.
+// when weapons and armor have been imported, imported says whether the
+// item was found among them by slug, and those which weren't are reported,
+// so
+//
+//	SELECT * FROM class_equipment WHERE NOT imported
+//
+// lists the starting equipment which can't be resolved.
+func writeClassEquipmentToDB(db sqlx.Execer, class ClassImport, items equipmentIndex) error {
+	for c, choice := range ParseEquipment(class.Equipment) {
+		for a, alternative := range choice.Alternatives {
+			for i, item := range alternative {
+				var imported interface{}
+				if found, known := items.imported(item); known {
+					imported = found
+					if !found {
+						fmt.Printf("Equipment of %s not imported: %s %q\n", class.Slug, item.Kind, item.Slug)
+					}
+				}
+				_, err := db.Exec(`
+					INSERT INTO class_equipment
+					(class_slug, choice, alternative, position, quantity, name, slug, kind, category, note, imported)
+					VALUES
+					(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				`, class.Slug, c+1, a+1, i, item.Quantity, item.Name, item.Slug, item.Kind, item.Category,
+					item.Note, imported)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package classes
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/open5e"
+)
+
+func TestParseEquipment(t *testing.T) {
+	equipment := "You start with the following equipment, in addition to the equipment granted by your background: \n \n" +
+		"* (*a*) a greataxe or (*b*) any martial melee weapon \n" +
+		"* *(a)* a light crossbow and 20 bolts or (*b*) a warhammer (if proficient) \n" +
+		"* A longbow and a quiver of 20 arrows"
+	want := []EquipmentChoice{
+		{Alternatives: [][]EquipmentItem{
+			{{Quantity: 1, Name: "greataxe", Slug: "greataxe", Kind: "weapon"}},
+			{{Quantity: 1, Name: "martial melee weapon", Slug: "martial-melee-weapon", Kind: "weapon", Category: true}},
+		}},
+		{Alternatives: [][]EquipmentItem{
+			{
+				{Quantity: 1, Name: "light crossbow", Slug: "light-crossbow", Kind: "weapon"},
+				{Quantity: 20, Name: "bolt", Slug: "bolt", Kind: "gear"},
+			},
+			{{Quantity: 1, Name: "warhammer", Slug: "warhammer", Kind: "weapon", Note: "if proficient"}},
+		}},
+		{Alternatives: [][]EquipmentItem{{
+			{Quantity: 1, Name: "longbow", Slug: "longbow", Kind: "weapon"},
+			{Quantity: 1, Name: "quiver", Slug: "quiver", Kind: "gear"},
+			{Quantity: 20, Name: "arrow", Slug: "arrow", Kind: "gear"},
+		}}},
+	}
+	if got := ParseEquipment(equipment); !reflect.DeepEqual(got, want) {
+		t.Errorf("got  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestEquipmentItem(t *testing.T) {
+	tests := []struct {
+		text string
+		want EquipmentItem
+	}{
+		{"two martial weapons", EquipmentItem{Quantity: 2, Name: "martial weapon", Slug: "martial-weapon", Kind: "weapon", Category: true}},
+		{"any other musical instrument", EquipmentItem{Quantity: 1, Name: "musical instrument", Slug: "musical-instrument", Kind: "gear", Category: true}},
+		{"an explorer's pack", EquipmentItem{Quantity: 1, Name: "explorer's pack", Slug: "explorers-pack", Kind: "pack"}},
+		{"a wooden shield", EquipmentItem{Quantity: 1, Name: "wooden shield", Slug: "wooden-shield", Kind: "armor"}},
+		{"10 darts", EquipmentItem{Quantity: 10, Name: "dart", Slug: "dart", Kind: "weapon"}},
+		{"chain mail", EquipmentItem{Quantity: 1, Name: "chain mail", Slug: "chain-mail", Kind: "armor"}},
+	}
+	for _, test := range tests {
+		if got := equipmentItem(test.text); got != test.want {
+			t.Errorf("%q = %+v, want %+v", test.text, got, test.want)
+		}
+	}
+}
+
+func TestClassEquipmentImported(t *testing.T) {
+	err := os.Remove("../../sql_database/equipment_test.db")
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	db, err := sqlx.Open("sqlite3", "../../sql_database/equipment_test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// weapon_imports would be owned by a weapons importer, so only the
+	// column the classes read is created here.  armor isn't imported.
+	_, err = db.Exec(`
+		CREATE TABLE weapon_imports (slug TEXT);
+		INSERT INTO weapon_imports (slug) VALUES ('greataxe'), ('handaxe');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	classes, _ := convertJsonToClassImports(data, open5e.V1)
+	if err := writeClassesToDB(db, classes[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	var items []string
+	err = db.Select(&items, `
+		SELECT choice || ' ' || alternative || ' ' || slug || ' ' || COALESCE(imported, '-')
+		FROM class_equipment WHERE class_slug = 'barbarian' ORDER BY id
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// categories and packs can't be found, and javelins weren't imported
+	want := []string{
+		"1 1 greataxe 1", "1 2 martial-melee-weapon -",
+		"2 1 handaxe 1", "2 2 simple-weapon -",
+		"3 1 explorers-pack -", "3 1 javelin 0",
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("barbarian equipment = %q, want %q", items, want)
+	}
+}
@@ -0,0 +1,208 @@
+package classes
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ClassFeature is a "### " section of a class's description.  "#### "
+// subsections, like the spellcasting rules or the fighting styles, stay in
+// the feature's Body.  Level is 0 when neither the class table nor the
+// feature's text gives it.
+type ClassFeature struct {
+	Name  string
+	Level int32
+	Body  string
+}
+
+// FeatureMismatch is a disagreement between a class's description and its
+// table.
+type FeatureMismatch struct {
+	Feature string
+	Problem string // "not in table", "not in description" or "level differs"
+}
+
+var (
+	featureHeading = regexp.MustCompile(`(?m)^###[ \t]+(.+?)[ \t]*$`)
+	featureLevel   = regexp.MustCompile(`(?i)(?:^|[.:]\s+|\n\s*)(?:at|starting at|beginning at|by|by the time you reach|when you reach|beginning when you reach|upon reaching|once you reach) (\d+)(?:st|nd|rd|th) level`)
+	featureParens  = regexp.MustCompile(`\s*\([^)]*\)`)
+)
+
+// ParseClassFeatures splits a class's description into its features, and
+// compares them with the features of its table.  a feature's level is the
+// first level of the table to list it, or else the level its text starts
+// with, e.g. "Starting at 2nd level, ...".  table entries for subclass
+// features, like "Path feature", aren't expected in the description.
+func ParseClassFeatures(description string, levels []ClassLevel) ([]ClassFeature, []FeatureMismatch) {
+	var features []ClassFeature
+	var mismatches []FeatureMismatch
+
+	headings := featureHeading.FindAllStringSubmatchIndex(description, -1)
+	for i, h := range headings {
+		end := len(description)
+		if i+1 < len(headings) {
+			end = headings[i+1][0]
+		}
+		feature := ClassFeature{
+			Name: description[h[2]:h[3]],
+			Body: strings.TrimSpace(description[h[1]:end]),
+		}
+
+		var textLevel int32
+		if m := featureLevel.FindStringSubmatch(feature.Body); m != nil {
+			textLevel = atoi32(m[1])
+		}
+		tableLevel := firstTableLevel(feature.Name, levels)
+		switch {
+		case tableLevel == 0 && len(levels) > 0:
+			mismatches = append(mismatches, FeatureMismatch{feature.Name, "not in table"})
+			feature.Level = textLevel
+		case tableLevel == 0:
+			feature.Level = textLevel
+		default:
+			feature.Level = tableLevel
+			if textLevel != 0 && textLevel != tableLevel {
+				mismatches = append(mismatches, FeatureMismatch{feature.Name, "level differs"})
+			}
+		}
+		features = append(features, feature)
+	}
+
+	var names []string
+	for _, feature := range features {
+		names = append(names, feature.Name)
+	}
+	var missing []string
+	for _, level := range levels {
+		for _, name := range level.Features {
+			if strings.HasSuffix(strings.ToLower(name), " feature") || contains(missing, name) {
+				continue
+			}
+			if matchFeature(name, names) == "" {
+				missing = append(missing, name)
+				mismatches = append(mismatches, FeatureMismatch{name, "not in description"})
+			}
+		}
+	}
+	return features, mismatches
+}
+
+// firstTableLevel is the first level of the table listing the feature, or
+// 0 when none do.
+func firstTableLevel(name string, levels []ClassLevel) int32 {
+	for _, exact := range []bool{true, false} {
+		for _, level := range levels {
+			for _, feature := range level.Features {
+				if sameFeature(name, feature, exact) {
+					return level.Level
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// matchFeature returns the name in names which is the same feature as
+// name, preferring an exact match.
+func matchFeature(name string, names []string) string {
+	for _, exact := range []bool{true, false} {
+		for _, candidate := range names {
+			if sameFeature(name, candidate, exact) {
+				return candidate
+			}
+		}
+	}
+	return ""
+}
+
+// sameFeature reports whether a and b name the same feature.  the table
+// and the description don't always agree on a feature's name, so unless
+// exact is set "Relentless" is the same as "Relentless Rage".  whatever is
+// in parentheses is ignored, so "Extra Attack (3)" is always the same as
+// "Extra Attack".
+func sameFeature(a, b string, exact bool) bool {
+	a, b = normalizeFeature(a), normalizeFeature(b)
+	if a == "" || b == "" {
+		return false
+	}
+	if exact {
+		return a == b
+	}
+	return strings.HasPrefix(a, b) || strings.HasPrefix(b, a)
+}
+
+func normalizeFeature(name string) string {
+	return strings.TrimSpace(strings.ToLower(featureParens.ReplaceAllString(name, "")))
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func createClassFeatureTables(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS class_features (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			class_slug TEXT,
+			position INTEGER,
+			name TEXT,
+			level INTEGER,
+			body TEXT
+		);
+		CREATE TABLE IF NOT EXISTS class_feature_mismatches (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			class_slug TEXT,
+			feature TEXT,
+			problem TEXT
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create class_features: %w", err)
+	}
+	return nil
+}
+
+// writes a row to class_features for each feature of the class's
+// description, and its disagreements with the class table to
+// class_feature_mismatches.  features without a level get a NULL one.
+func writeClassFeaturesToDB(db sqlx.Execer, class ClassImport) error {
+	// writeClassLevelsToDB has already reported a table it doesn't understand
+	levels, _ := ParseClassTable(class.Table)
+	features, mismatches := ParseClassFeatures(class.Description, levels)
+	for i, feature := range features {
+		var level interface{}
+		if feature.Level != 0 {
+			level = feature.Level
+		}
+		_, err := db.Exec(`
+			INSERT INTO class_features
+			(class_slug, position, name, level, body)
+			VALUES
+			(?, ?, ?, ?, ?)
+		`, class.Slug, i, feature.Name, level, feature.Body)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, mismatch := range mismatches {
+		_, err := db.Exec(`
+			INSERT INTO class_feature_mismatches
+			(class_slug, feature, problem)
+			VALUES
+			(?, ?, ?)
+		`, class.Slug, mismatch.Feature, mismatch.Problem)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package classes
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"open5e_importer/open5e"
+)
+
+func TestParseClassFeatures(t *testing.T) {
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	classes, _ := convertJsonToClassImports(data, open5e.V1)
+
+	levelsOf := map[string]map[string]int32{}
+	mismatches := map[string][]FeatureMismatch{}
+	for _, class := range classes {
+		table, _ := ParseClassTable(class.Table)
+		features, found := ParseClassFeatures(class.Description, table)
+		levelsOf[class.Slug] = map[string]int32{}
+		for _, feature := range features {
+			if feature.Body == "" {
+				t.Errorf("%s: %s has no body", class.Slug, feature.Name)
+			}
+			levelsOf[class.Slug][feature.Name] = feature.Level
+		}
+		if len(found) > 0 {
+			mismatches[class.Slug] = found
+		}
+	}
+
+	tests := []struct {
+		class   string
+		feature string
+		level   int32
+	}{
+		{"barbarian", "Rage", 1},
+		{"barbarian", "Relentless Rage", 11},
+		{"barbarian", "Ability Score Improvement", 4},
+		{"cleric", "Channel Divinity", 2},
+		{"fighter", "Extra Attack", 5},
+		{"monk", "Ki", 2},
+		{"monk", "Ki-Empowered Strikes", 6},
+		{"wizard", "Signature Spells", 20},
+		// only the text gives the level of a feature missing from the table
+		{"druid", "Ritual Casting", 0},
+	}
+	for _, test := range tests {
+		level, ok := levelsOf[test.class][test.feature]
+		if !ok {
+			t.Errorf("%s: no %s feature", test.class, test.feature)
+		} else if level != test.level {
+			t.Errorf("%s: %s at level %d, want %d", test.class, test.feature, level, test.level)
+		}
+	}
+
+	want := map[string][]FeatureMismatch{
+		"druid":   {{"Ritual Casting", "not in table"}},
+		"paladin": {{"Aura improvements", "not in description"}},
+	}
+	if !reflect.DeepEqual(mismatches, want) {
+		t.Errorf("mismatches = %v, want %v", mismatches, want)
+	}
+}
+
+func TestFeatureLevelDiffers(t *testing.T) {
+	table := []ClassLevel{{Level: 1, Features: []string{"Rage"}}, {Level: 2, Features: []string{"Danger Sense"}}}
+	description := "### Rage \n \nIn battle, you fight with primal ferocity.\n\n### Danger Sense \n \nAt 3rd level, you gain an uncanny sense."
+	features, mismatches := ParseClassFeatures(description, table)
+	if len(features) != 2 || features[1].Level != 2 {
+		t.Errorf("unexpected features %+v", features)
+	}
+	if want := []FeatureMismatch{{"Danger Sense", "level differs"}}; !reflect.DeepEqual(mismatches, want) {
+		t.Errorf("mismatches = %v, want %v", mismatches, want)
+	}
+}
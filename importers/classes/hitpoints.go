@@ -0,0 +1,96 @@
+package classes
+
+import (
+	"fmt"
+	"regexp"
+
+	"open5e_importer/dice"
+)
+
+// HitPoints is a class's hit point progression: FirstLevel hit points at
+// 1st level, and a HitDie sized die, or PerLevel fixed, at each level
+// after.  the Constitution modifier is added at every level.
+type HitPoints struct {
+	FirstLevel int32
+	HitDie     int32
+	PerLevel   int32
+}
+
+var (
+	hpFirstLevel = regexp.MustCompile(`^\s*(\d+)\b`)
+	hpFixed      = regexp.MustCompile(`\(or (\d+)\)`)
+)
+
+// ParseHitPoints reads a class's hit_dice, hp_at_1st_level ("12 + your
+// Constitution modifier") and hp_at_higher_levels ("1d12 (or 7) + your
+// Constitution modifier per barbarian level after 1st").  the fixed hit
+// points are the die's average rounded up when the class doesn't give
+// them.
+func ParseHitPoints(class ClassImport) (HitPoints, error) {
+	var hp HitPoints
+	expr, err := dice.Parse(class.HitDice)
+	if err != nil {
+		return hp, err
+	}
+	if len(expr.Terms) != 1 || expr.Terms[0].Sides == 0 {
+		return hp, fmt.Errorf("hit dice %q isn't a single die", class.HitDice)
+	}
+	hp.HitDie = int32(expr.Terms[0].Sides)
+
+	m := hpFirstLevel.FindStringSubmatch(class.HpAtFirstLevel)
+	if m == nil {
+		return hp, fmt.Errorf("hit points at 1st level %q not understood", class.HpAtFirstLevel)
+	}
+	hp.FirstLevel = atoi32(m[1])
+
+	hp.PerLevel = hp.HitDie/2 + 1
+	if m := hpFixed.FindStringSubmatch(class.HpAtHigherLevels); m != nil {
+		hp.PerLevel = atoi32(m[1])
+	}
+	return hp, nil
+}
+
+// MaxHP is the maximum hit points of a character of level in the class,
+// taking the fixed hit points at each level after the 1st.  no level adds
+// fewer than 1 hit point, however low conModifier is.
+func (hp HitPoints) MaxHP(level, conModifier int32) int32 {
+	if level < 1 {
+		return 0
+	}
+	return atLeastOne(hp.FirstLevel+conModifier) + (level-1)*atLeastOne(hp.PerLevel+conModifier)
+}
+
+// ClassLevels is a number of levels taken in a class.
+type ClassLevels struct {
+	HitPoints HitPoints
+	Levels    int32
+}
+
+// MulticlassMaxHP is the maximum hit points of a character with levels in
+// each of classes.  the first class with a level is the one the character
+// started in, and the only one to give its 1st level hit points.  classes
+// without a level add nothing.
+func MulticlassMaxHP(classes []ClassLevels, conModifier int32) int32 {
+	var total int32
+	started := false
+	for _, class := range classes {
+		if class.Levels < 1 {
+			continue
+		}
+		levels := class.Levels
+		if !started {
+			total += atLeastOne(class.HitPoints.FirstLevel + conModifier)
+			levels--
+			started = true
+		}
+		total += levels * atLeastOne(class.HitPoints.PerLevel+conModifier)
+	}
+	return total
+}
+
+func atLeastOne(hp int32) int32 {
+	if hp < 1 {
+		return 1
+	}
+	return hp
+}
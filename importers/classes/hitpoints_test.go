@@ -0,0 +1,75 @@
+package classes
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"open5e_importer/open5e"
+)
+
+func TestParseHitPoints(t *testing.T) {
+	for _, version := range []open5e.Version{open5e.V1, open5e.V2} {
+		file := "./test_data/testdata.json"
+		if version == open5e.V2 {
+			file = "./test_data/testdata_v2.json"
+		}
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		classes, _ := convertJsonToClassImports(data, version)
+		for _, class := range classes {
+			if class.SubclassOf != "" {
+				continue
+			}
+			hp, err := ParseHitPoints(class)
+			if err != nil {
+				t.Errorf("%s %s: %v", version, class.Slug, err)
+			}
+			if hp.FirstLevel != hp.HitDie || hp.PerLevel != hp.HitDie/2+1 {
+				t.Errorf("%s %s: unexpected %+v", version, class.Slug, hp)
+			}
+		}
+	}
+
+	if _, err := ParseHitPoints(ClassImport{HitDice: "1d8", HpAtFirstLevel: "your Constitution score"}); err == nil {
+		t.Error("expected an error for hit points without a number")
+	}
+}
+
+func TestMaxHP(t *testing.T) {
+	barbarian := HitPoints{FirstLevel: 12, HitDie: 12, PerLevel: 7}
+	wizard := HitPoints{FirstLevel: 6, HitDie: 6, PerLevel: 4}
+	tests := []struct {
+		hp    HitPoints
+		level int32
+		con   int32
+		want  int32
+	}{
+		{barbarian, 1, 2, 14},
+		{barbarian, 5, 2, 14 + 4*9},
+		{wizard, 3, -1, 5 + 2*3},
+		// every level gives at least 1
+		{wizard, 4, -5, 4},
+		{wizard, 0, 3, 0},
+	}
+	for _, test := range tests {
+		if got := test.hp.MaxHP(test.level, test.con); got != test.want {
+			t.Errorf("%+v level %d con %d = %d, want %d", test.hp, test.level, test.con, got, test.want)
+		}
+	}
+
+	// a barbarian 3 / wizard 2 who started as a barbarian
+	multi := []ClassLevels{{barbarian, 3}, {wizard, 2}}
+	if got, want := MulticlassMaxHP(multi, 1), int32(13+2*8+2*5); got != want {
+		t.Errorf("multiclass = %d, want %d", got, want)
+	}
+	if got, want := MulticlassMaxHP(multi[:1], 1), barbarian.MaxHP(3, 1); got != want {
+		t.Errorf("single class = %d, want %d", got, want)
+	}
+	// a class without levels listed first isn't the one started in
+	unstarted := append([]ClassLevels{{HitPoints: multi[1].HitPoints}}, multi[0])
+	if got, want := MulticlassMaxHP(unstarted, 1), barbarian.MaxHP(3, 1); got != want {
+		t.Errorf("class without levels first = %d, want %d", got, want)
+	}
+}
@@ -0,0 +1,271 @@
+package classes
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Proficiency is a proficiency a class grants, or one of the options of a
+// choice.  when Category is set Name is a group, like "martial weapons" or
+// "musical instrument", rather than a single item.  Known is false for
+// names which aren't in the lists below.
+type Proficiency struct {
+	Kind     string // "armor", "weapon", "tool", "saving_throw" or "skill"
+	Name     string
+	Category bool
+	Note     string // anything the class says about it in parentheses
+	Known    bool
+}
+
+// ProficiencyChoice is a "Choose two from ..." choice, where Choose of the
+// Options are picked.
+type ProficiencyChoice struct {
+	Kind    string
+	Choose  int32
+	Options []Proficiency
+}
+
+// ClassProficiencies are the proficiencies granted by a class's
+// prof_armor, prof_weapons, prof_tools, prof_saving_throws and prof_skills.
+type ClassProficiencies struct {
+	Fixed   []Proficiency
+	Choices []ProficiencyChoice
+}
+
+// skills are keyed the way monster skills are
+var knownSkills = map[string]string{
+	"acrobatics": "acrobatics", "animal handling": "animal_handling", "arcana": "arcana",
+	"athletics": "athletics", "deception": "deception", "history": "history",
+	"insight": "insight", "intimidation": "intimidation", "investigation": "investigation",
+	"medicine": "medicine", "nature": "nature", "perception": "perception",
+	"performance": "performance", "persuasion": "persuasion", "religion": "religion",
+	"sleight of hand": "sleight_of_hand", "stealth": "stealth", "survival": "survival",
+}
+
+var knownAbilities = []string{"strength", "dexterity", "constitution", "intelligence", "wisdom", "charisma"}
+
+var knownArmor = []string{"light armor", "medium armor", "heavy armor", "shields"}
+
+var knownWeapons = []string{
+	"club", "dagger", "greatclub", "handaxe", "javelin", "light hammer", "mace", "quarterstaff",
+	"sickle", "spear", "light crossbow", "dart", "shortbow", "sling",
+	"battleaxe", "flail", "glaive", "greataxe", "greatsword", "halberd", "lance", "longsword",
+	"maul", "morningstar", "pike", "rapier", "scimitar", "shortsword", "trident", "war pick",
+	"warhammer", "whip", "blowgun", "hand crossbow", "heavy crossbow", "longbow", "net",
+}
+
+var knownTools = []string{
+	"alchemist's supplies", "brewer's supplies", "calligrapher's supplies", "carpenter's tools",
+	"cartographer's tools", "cobbler's tools", "cook's utensils", "glassblower's tools",
+	"jeweler's tools", "leatherworker's tools", "mason's tools", "painter's supplies",
+	"potter's tools", "smith's tools", "tinker's tools", "weaver's tools", "woodcarver's tools",
+	"disguise kit", "forgery kit", "herbalism kit", "navigator's tools", "poisoner's kit",
+	"thieves' tools",
+}
+
+// tool groups a class can let you choose from
+var toolCategories = []string{"artisan's tools", "gaming set", "musical instrument"}
+
+var (
+	profParens  = regexp.MustCompile(`\s*\(([^)]*)\)`)
+	profChoose  = regexp.MustCompile(`(?i)^choose (any )?(\w+)(?: skills?)?(?: from)?\s*(.*)$`)
+	profOfYours = regexp.MustCompile(`(?i)^(\w+) (.+?) of your choice$`)
+	profSplit   = regexp.MustCompile(`\s*,\s*(?:and |or )?|\s+(?:and|or)\s+`)
+	profCount   = regexp.MustCompile(`^(?:(?:one|two|three|four|five|six|\d+) )?(?:types? of )?`)
+)
+
+var numberWords = map[string]int32{"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6}
+
+// ParseClassProficiencies reads a class's proficiency fields.
+func ParseClassProficiencies(class ClassImport) ClassProficiencies {
+	var profs ClassProficiencies
+	for _, field := range []struct {
+		kind string
+		text string
+	}{
+		{"armor", class.ProficienciesArmor},
+		{"weapon", class.ProficienciesWeapons},
+		{"tool", class.ProficienciesTools},
+		{"saving_throw", class.ProficienciesSavingThrows},
+		{"skill", class.ProficienciesSkills},
+	} {
+		profs.read(field.kind, field.text)
+	}
+	return profs
+}
+
+// read adds the proficiencies of one field, which is either "None", a
+// list of fixed proficiencies or a single choice.
+func (profs *ClassProficiencies) read(kind, text string) {
+	text = strings.TrimSuffix(strings.TrimSpace(text), ".")
+	if text == "" || strings.EqualFold(text, "none") {
+		return
+	}
+
+	if m := profChoose.FindStringSubmatch(text); m != nil {
+		choice := ProficiencyChoice{Kind: kind, Choose: countWord(m[2])}
+		if m[1] != "" || m[3] == "" {
+			// "Choose any three"
+			choice.Options = []Proficiency{{Kind: kind, Name: kind, Category: true, Known: true}}
+		} else {
+			choice.Options = proficiencyList(kind, m[3])
+		}
+		profs.Choices = append(profs.Choices, choice)
+		return
+	}
+	if m := profOfYours.FindStringSubmatch(text); m != nil && countWord(m[1]) != 0 {
+		// "Three musical instruments of your choice"
+		profs.Choices = append(profs.Choices, ProficiencyChoice{
+			Kind:    kind,
+			Choose:  countWord(m[1]),
+			Options: proficiencyList(kind, m[2]),
+		})
+		return
+	}
+	profs.Fixed = append(profs.Fixed, proficiencyList(kind, text)...)
+}
+
+// proficiencyList splits a list of proficiencies at its commas, "and"s and
+// "or"s, keeping what's in parentheses as a note on the item before it.
+// "all armor" is every armor category.
+func proficiencyList(kind, text string) []Proficiency {
+	// notes are swapped for a marker first, as they have commas of their own
+	var notes []string
+	text = profParens.ReplaceAllStringFunc(text, func(paren string) string {
+		notes = append(notes, profParens.FindStringSubmatch(paren)[1])
+		return fmt.Sprintf("\x00%d", len(notes)-1)
+	})
+
+	var list []Proficiency
+	items := profSplit.Split(text, -1)
+	for i := 0; i < len(items); i++ {
+		item, note := items[i], ""
+		if marker := strings.IndexByte(item, 0); marker >= 0 {
+			item, note = item[:marker], notes[atoi32(item[marker+1:])]
+		}
+		item = profCount.ReplaceAllString(strings.ToLower(strings.TrimSpace(item)), "")
+		// a stray comma in "Animal, Handling"
+		if item == "animal" && i+1 < len(items) && strings.EqualFold(strings.TrimSpace(items[i+1]), "handling") {
+			item, i = "animal handling", i+1
+		}
+		if item == "" {
+			continue
+		}
+		if kind == "armor" && item == "all armor" {
+			for _, armor := range knownArmor[:3] {
+				list = append(list, Proficiency{Kind: kind, Name: armor, Category: true, Note: note, Known: true})
+			}
+			continue
+		}
+		prof := normalizeProficiency(kind, item)
+		prof.Note = note
+		list = append(list, prof)
+	}
+	return list
+}
+
+// normalizeProficiency looks up a lower cased item in the list of its
+// kind.  weapons are singular, except the "simple weapons" and "martial
+// weapons" categories.
+func normalizeProficiency(kind, item string) Proficiency {
+	prof := Proficiency{Kind: kind, Name: item}
+	switch kind {
+	case "armor":
+		if item == "shield" {
+			prof.Name = "shields"
+		}
+		prof.Category = true
+		prof.Known = contains(knownArmor, prof.Name)
+	case "weapon":
+		if item == "simple weapons" || item == "martial weapons" {
+			prof.Category, prof.Known = true, true
+			break
+		}
+		if !contains(knownWeapons, item) {
+			prof.Name = strings.TrimSuffix(item, "s")
+		}
+		prof.Known = contains(knownWeapons, prof.Name)
+	case "tool":
+		for _, category := range toolCategories {
+			if item == category || item == category+"s" {
+				prof.Name, prof.Category, prof.Known = category, true, true
+			}
+		}
+		if !prof.Category {
+			prof.Known = contains(knownTools, item)
+		}
+	case "saving_throw":
+		prof.Known = contains(knownAbilities, item)
+	case "skill":
+		if key, ok := knownSkills[item]; ok {
+			prof.Name, prof.Known = key, true
+		}
+	}
+	return prof
+}
+
+func countWord(word string) int32 {
+	word = strings.ToLower(word)
+	if n, ok := numberWords[word]; ok {
+		return n
+	}
+	return atoi32(word)
+}
+
+func createClassProficiencyTable(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS class_proficiencies (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			class_slug TEXT,
+			kind TEXT,
+			choice INTEGER,
+			choose INTEGER,
+			name TEXT,
+			category BOOLEAN,
+			note TEXT,
+			known BOOLEAN
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create class_proficiencies: %w", err)
+	}
+	return nil
+}
+
+// writes a row to class_proficiencies for each proficiency the class
+// grants, with choice and choose NULL, and for each option of its choices,
+// numbered from 1 by choice.  names which aren't known are reported.
+func writeClassProficienciesToDB(db sqlx.Execer, class ClassImport) error {
+	profs := ParseClassProficiencies(class)
+	write := func(prof Proficiency, choice, choose interface{}) error {
+		if !prof.Known {
+			fmt.Printf("Proficiency of %s not understood: %s %q\n", class.Slug, prof.Kind, prof.Name)
+		}
+		_, err := db.Exec(`
+			INSERT INTO class_proficiencies
+			(class_slug, kind, choice, choose, name, category, note, known)
+			VALUES
+			(?, ?, ?, ?, ?, ?, ?, ?)
+		`, class.Slug, prof.Kind, choice, choose, prof.Name, prof.Category, prof.Note, prof.Known)
+		return err
+	}
+
+	for _, prof := range profs.Fixed {
+		err := write(prof, nil, nil)
+		if err != nil {
+			return err
+		}
+	}
+	for i, choice := range profs.Choices {
+		for _, option := range choice.Options {
+			err := write(option, i+1, choice.Choose)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package classes
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"open5e_importer/open5e"
+)
+
+func TestParseClassProficiencies(t *testing.T) {
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	classes, _ := convertJsonToClassImports(data, open5e.V1)
+	parsed := map[string]ClassProficiencies{}
+	for _, class := range classes {
+		profs := ParseClassProficiencies(class)
+		for _, prof := range profs.Fixed {
+			if !prof.Known {
+				t.Errorf("%s: unknown %s %q", class.Slug, prof.Kind, prof.Name)
+			}
+		}
+		for _, choice := range profs.Choices {
+			for _, prof := range choice.Options {
+				if !prof.Known {
+					t.Errorf("%s: unknown %s option %q", class.Slug, prof.Kind, prof.Name)
+				}
+			}
+		}
+		parsed[class.Slug] = profs
+	}
+
+	skill := func(name string) Proficiency { return Proficiency{Kind: "skill", Name: name, Known: true} }
+	tests := []struct {
+		class string
+		want  ClassProficiencies
+	}{
+		{"barbarian", ClassProficiencies{
+			Fixed: []Proficiency{
+				{Kind: "armor", Name: "light armor", Category: true, Known: true},
+				{Kind: "armor", Name: "medium armor", Category: true, Known: true},
+				{Kind: "armor", Name: "shields", Category: true, Known: true},
+				{Kind: "weapon", Name: "simple weapons", Category: true, Known: true},
+				{Kind: "weapon", Name: "martial weapons", Category: true, Known: true},
+				{Kind: "saving_throw", Name: "strength", Known: true},
+				{Kind: "saving_throw", Name: "constitution", Known: true},
+			},
+			Choices: []ProficiencyChoice{{Kind: "skill", Choose: 2, Options: []Proficiency{
+				skill("animal_handling"), skill("athletics"), skill("intimidation"),
+				skill("nature"), skill("perception"), skill("survival"),
+			}}},
+		}},
+		{"bard", ClassProficiencies{
+			Fixed: []Proficiency{
+				{Kind: "armor", Name: "light armor", Category: true, Known: true},
+				{Kind: "weapon", Name: "simple weapons", Category: true, Known: true},
+				{Kind: "weapon", Name: "hand crossbow", Known: true},
+				{Kind: "weapon", Name: "longsword", Known: true},
+				{Kind: "weapon", Name: "rapier", Known: true},
+				{Kind: "weapon", Name: "shortsword", Known: true},
+				{Kind: "saving_throw", Name: "dexterity", Known: true},
+				{Kind: "saving_throw", Name: "charisma", Known: true},
+			},
+			Choices: []ProficiencyChoice{
+				{Kind: "tool", Choose: 3, Options: []Proficiency{{Kind: "tool", Name: "musical instrument", Category: true, Known: true}}},
+				{Kind: "skill", Choose: 3, Options: []Proficiency{{Kind: "skill", Name: "skill", Category: true, Known: true}}},
+			},
+		}},
+		{"monk", ClassProficiencies{
+			Fixed: []Proficiency{
+				{Kind: "weapon", Name: "simple weapons", Category: true, Known: true},
+				{Kind: "weapon", Name: "shortsword", Known: true},
+				{Kind: "saving_throw", Name: "strength", Known: true},
+				{Kind: "saving_throw", Name: "dexterity", Known: true},
+			},
+			Choices: []ProficiencyChoice{
+				{Kind: "tool", Choose: 1, Options: []Proficiency{
+					{Kind: "tool", Name: "artisan's tools", Category: true, Known: true},
+					{Kind: "tool", Name: "musical instrument", Category: true, Known: true},
+				}},
+				{Kind: "skill", Choose: 2, Options: []Proficiency{
+					skill("acrobatics"), skill("athletics"), skill("history"),
+					skill("insight"), skill("religion"), skill("stealth"),
+				}},
+			},
+		}},
+	}
+	for _, test := range tests {
+		if got := parsed[test.class]; !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s:\ngot  %+v\nwant %+v", test.class, got, test.want)
+		}
+	}
+
+	// "Animal, Handling" and the note on the druid's shields
+	fighter := parsed["fighter"].Choices[0].Options
+	if len(fighter) != 8 || fighter[1] != skill("animal_handling") {
+		t.Errorf("fighter skills = %+v", fighter)
+	}
+	druid := parsed["druid"].Fixed[2]
+	if druid.Name != "shields" || druid.Note != "druids will not wear armor or use shields made of metal" {
+		t.Errorf("druid shields = %+v", druid)
+	}
+}
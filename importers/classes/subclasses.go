@@ -0,0 +1,161 @@
+package classes
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Subclass is one of a class's archetypes, e.g. the barbarian's "Path of
+// the Berserker".
+type Subclass struct {
+	ClassSlug    string
+	Slug         string
+	Name         string
+	DocumentSlug string
+	Description  string
+}
+
+var (
+	subclassHeading = regexp.MustCompile(`(?m)^[ \t]*#{3,5}[ \t]*([^#\s][^\n]*?)[ \t]*$`)
+	subclassLevel   = regexp.MustCompile(`(?i)(?:^|[.:]\s+|\n\s*)(?:also )?(?:at|from|starting|beginning|by|when|once|upon)\b[^.]*?(\d+)(?:st|nd|rd|th) level`)
+)
+
+// classSubclasses returns the archetypes v1 lists on the class.
+func classSubclasses(class ClassImport) []Subclass {
+	var subclasses []Subclass
+	for _, archetype := range class.Archetypes {
+		obj, ok := archetype.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		subclass := Subclass{ClassSlug: class.Slug}
+		subclass.Slug, _ = obj["slug"].(string)
+		subclass.Name, _ = obj["name"].(string)
+		subclass.DocumentSlug, _ = obj["document__slug"].(string)
+		subclass.Description, _ = obj["desc"].(string)
+		subclasses = append(subclasses, subclass)
+	}
+	return subclasses
+}
+
+// ParseSubclassFeatures splits an archetype's description into its
+// features, which are its "#####" headings, e.g. "##### Frenzy".  a
+// feature's level is the one its text starts with, e.g. "Starting when you
+// choose this path at 3rd level, ...".  features which don't say, like an
+// oath's tenets, are gained with the subclass, at the level of its first
+// feature that does.
+func ParseSubclassFeatures(description string) []ClassFeature {
+	var features []ClassFeature
+	headings := subclassHeading.FindAllStringSubmatchIndex(description, -1)
+	for i, h := range headings {
+		end := len(description)
+		if i+1 < len(headings) {
+			end = headings[i+1][0]
+		}
+		feature := ClassFeature{
+			Name: strings.TrimSuffix(description[h[2]:h[3]], "."),
+			Body: strings.TrimSpace(description[h[1]:end]),
+		}
+		if m := subclassLevel.FindStringSubmatch(feature.Body); m != nil {
+			feature.Level = atoi32(m[1])
+		}
+		features = append(features, feature)
+	}
+
+	var first int32
+	for _, feature := range features {
+		if feature.Level != 0 && (first == 0 || feature.Level < first) {
+			first = feature.Level
+		}
+	}
+	for i := range features {
+		if features[i].Level == 0 {
+			features[i].Level = first
+		}
+	}
+	return features
+}
+
+func createSubclassTables(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS subclass_imports (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			class_slug TEXT,
+			slug TEXT,
+			name TEXT,
+			document_slug TEXT REFERENCES documents(slug),
+			description TEXT
+		);
+		CREATE TABLE IF NOT EXISTS subclass_features (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			subclass_slug TEXT,
+			position INTEGER,
+			name TEXT,
+			level INTEGER,
+			body TEXT
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create subclass_imports: %w", err)
+	}
+	return nil
+}
+
+// writes the subclass to subclass_imports, with a row to subclass_features
+// for each of its features, after deleting what an earlier import wrote for
+// it.  features of a subclass without any levels get a NULL one.
+func writeSubclassToDB(db sqlx.Execer, subclass Subclass) error {
+	for _, query := range []string{
+		`DELETE FROM subclass_features WHERE subclass_slug = ?`,
+		`DELETE FROM subclass_imports WHERE slug = ?`,
+	} {
+		_, err := db.Exec(query, subclass.Slug)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := db.Exec(`
+		INSERT INTO subclass_imports
+		(class_slug, slug, name, document_slug, description)
+		VALUES
+		(?, ?, ?, ?, ?)
+	`, subclass.ClassSlug, subclass.Slug, subclass.Name, subclass.DocumentSlug, subclass.Description)
+	if err != nil {
+		return err
+	}
+
+	for i, feature := range ParseSubclassFeatures(subclass.Description) {
+		var level interface{}
+		if feature.Level != 0 {
+			level = feature.Level
+		}
+		_, err = db.Exec(`
+			INSERT INTO subclass_features
+			(subclass_slug, position, name, level, body)
+			VALUES
+			(?, ?, ?, ?, ?)
+		`, subclass.Slug, i, feature.Name, level, feature.Body)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deletes every subclass of the class and their features.
+func deleteClassSubclassesFromDB(db sqlx.Execer, classSlug string) error {
+	for _, query := range []string{
+		`DELETE FROM subclass_features WHERE subclass_slug IN (SELECT slug FROM subclass_imports WHERE class_slug = ?)`,
+		`DELETE FROM subclass_imports WHERE class_slug = ?`,
+	} {
+		_, err := db.Exec(query, classSlug)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package classes
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"open5e_importer/open5e"
+)
+
+func TestParseSubclassFeatures(t *testing.T) {
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	classes, _ := convertJsonToClassImports(data, open5e.V1)
+
+	subclasses := map[string]map[string]int32{}
+	count := 0
+	for _, class := range classes {
+		for _, subclass := range classSubclasses(class) {
+			count++
+			if subclass.ClassSlug != class.Slug || subclass.Name == "" || subclass.DocumentSlug == "" {
+				t.Errorf("unexpected subclass %+v", subclass)
+			}
+			subclasses[subclass.Slug] = map[string]int32{}
+			for _, feature := range ParseSubclassFeatures(subclass.Description) {
+				subclasses[subclass.Slug][feature.Name] = feature.Level
+			}
+		}
+	}
+	if count != 94 {
+		t.Errorf("%d subclasses, want 94", count)
+	}
+
+	tests := []struct {
+		subclass string
+		feature  string
+		level    int32
+	}{
+		{"path-of-the-berserker", "Frenzy", 3},
+		{"path-of-the-berserker", "Mindless Rage", 6},
+		{"path-of-the-berserker", "Intimidating Presence", 10},
+		{"path-of-the-berserker", "Retaliation", 14},
+		// no space after the hashes
+		{"path-of-hellfire", "Hell's Vengeance", 6},
+		// "Also at 3rd level"
+		{"college-of-lore", "Cutting Words", 3},
+		{"blood-domain", "Bloodletting Focus", 1},
+		// the tenets don't give a level, so come with the oath
+		{"oath-of-devotion", "Tenets of Devotion", 3},
+	}
+	for _, test := range tests {
+		level, ok := subclasses[test.subclass][test.feature]
+		if !ok {
+			t.Errorf("%s: no %s feature", test.subclass, test.feature)
+		} else if level != test.level {
+			t.Errorf("%s: %s at level %d, want %d", test.subclass, test.feature, level, test.level)
+		}
+	}
+}
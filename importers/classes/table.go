@@ -0,0 +1,235 @@
+package classes
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ClassLevel is a row of a class's markdown table, e.g. "| 5th | +3 |
+// Extra Attack, Fast Movement | 3 | +2 |".
+type ClassLevel struct {
+	Level            int32
+	ProficiencyBonus int32
+	Features         []string
+	Values           []ClassLevelValue // the class's own columns
+}
+
+// ClassLevelValue is one of a class's own columns at a level, e.g. the
+// barbarian's "Rage Damage" or a caster's "1st" level spell slots.  Key is
+// the column heading in snake case, with spell slot columns keyed
+// "spell_slots_1" to "spell_slots_9".  columns showing "-" at a level have
+// no value there.
+type ClassLevelValue struct {
+	Key    string
+	Kind   string // "number", "dice", "feet", "unlimited" or "text"
+	Number int32  // the value of numbers, feet and ordinals like "5th"
+	Dice   string
+	Text   string // the cell as written
+}
+
+var (
+	tableOrdinal = regexp.MustCompile(`^(\d+)(?:st|nd|rd|th)$`)
+	tableNumber  = regexp.MustCompile(`^[+-]?\d+$`)
+	tableDice    = regexp.MustCompile(`^\d+d\d+$`)
+	tableFeet    = regexp.MustCompile(`^\+?(\d+) ft\.?$`)
+	tableKey     = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// ParseClassTable reads a class's markdown table into its levels.  the
+// table must have a "Level" column, the other columns are found by their
+// heading.  a table it doesn't understand gives no levels at all, rather
+// than the ones before the problem.
+func ParseClassTable(table string) ([]ClassLevel, error) {
+	var rows [][]string
+	for _, line := range strings.Split(table, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "|") {
+			continue
+		}
+		cells := strings.Split(strings.Trim(line, "|"), "|")
+		for i := range cells {
+			cells[i] = strings.TrimSpace(cells[i])
+		}
+		// the |---|---| line under the headings
+		if strings.Trim(strings.Join(cells, ""), "-: ") == "" {
+			continue
+		}
+		rows = append(rows, cells)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	headings := rows[0]
+	if !strings.EqualFold(headings[0], "level") {
+		return nil, fmt.Errorf("first column is %q, not Level", headings[0])
+	}
+
+	var levels []ClassLevel
+	for _, row := range rows[1:] {
+		m := tableOrdinal.FindStringSubmatch(row[0])
+		if m == nil {
+			return nil, fmt.Errorf("level %q not understood", row[0])
+		}
+		level := ClassLevel{Level: atoi32(m[1])}
+		for i, cell := range row[1:] {
+			if i+1 >= len(headings) {
+				break
+			}
+			heading := headings[i+1]
+			switch {
+			case strings.EqualFold(heading, "proficiency bonus"):
+				level.ProficiencyBonus = atoi32(cell)
+			case strings.EqualFold(heading, "features"):
+				level.Features = splitFeatures(cell)
+			case cell == "-" || cell == "":
+			default:
+				value := tableValue(cell)
+				value.Key = strings.Trim(tableKey.ReplaceAllString(strings.ToLower(heading), "_"), "_")
+				if m := tableOrdinal.FindStringSubmatch(heading); m != nil {
+					value.Key = "spell_slots_" + m[1]
+				}
+				level.Values = append(level.Values, value)
+			}
+		}
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+// tableValue types a cell of one of a class's own columns.
+func tableValue(cell string) ClassLevelValue {
+	value := ClassLevelValue{Kind: "text", Text: cell}
+	if m := tableOrdinal.FindStringSubmatch(cell); m != nil {
+		value.Kind, value.Number = "number", atoi32(m[1])
+	} else if tableNumber.MatchString(cell) {
+		value.Kind, value.Number = "number", atoi32(cell)
+	} else if tableDice.MatchString(cell) {
+		value.Kind, value.Dice = "dice", cell
+	} else if m := tableFeet.FindStringSubmatch(cell); m != nil {
+		value.Kind, value.Number = "feet", atoi32(m[1])
+	} else if strings.EqualFold(cell, "unlimited") {
+		value.Kind = "unlimited"
+	}
+	return value
+}
+
+// splitFeatures splits a table's features cell at the commas outside of
+// parentheses, e.g. "Channel Divinity (1/rest), Divine Domain Feature".
+func splitFeatures(cell string) []string {
+	var features []string
+	depth, start := 0, 0
+	add := func(feature string) {
+		feature = strings.TrimSpace(feature)
+		if feature != "" && feature != "-" {
+			features = append(features, feature)
+		}
+	}
+	for i, r := range cell {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				add(cell[start:i])
+				start = i + 1
+			}
+		}
+	}
+	add(cell[start:])
+	return features
+}
+
+func atoi32(s string) int32 {
+	n, err := strconv.Atoi(strings.TrimPrefix(s, "+"))
+	if err != nil {
+		return 0
+	}
+	return int32(n)
+}
+
+func createClassLevelTables(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS class_levels (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			class_slug TEXT,
+			level INTEGER,
+			proficiency_bonus INTEGER,
+			features TEXT
+		);
+		CREATE TABLE IF NOT EXISTS class_level_features (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			class_slug TEXT,
+			level INTEGER,
+			position INTEGER,
+			feature TEXT
+		);
+		CREATE TABLE IF NOT EXISTS class_level_values (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			class_slug TEXT,
+			level INTEGER,
+			key TEXT,
+			kind TEXT,
+			number INTEGER,
+			dice TEXT,
+			text TEXT
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create class_levels: %w", err)
+	}
+	return nil
+}
+
+// writes a row to class_levels for each level of the class's table, with
+// the features gained at it in class_level_features and its class specific
+// columns in class_level_values.  v2 classes have no table, and a table
+// which isn't understood is reported and written without levels.
+func writeClassLevelsToDB(db sqlx.Execer, class ClassImport) error {
+	levels, err := ParseClassTable(class.Table)
+	if err != nil {
+		fmt.Printf("Table of %s not understood: %v\n", class.Slug, err)
+	}
+	for _, level := range levels {
+		_, err := db.Exec(`
+			INSERT INTO class_levels
+			(class_slug, level, proficiency_bonus, features)
+			VALUES
+			(?, ?, ?, ?)
+		`, class.Slug, level.Level, level.ProficiencyBonus, strings.Join(level.Features, ", "))
+		if err != nil {
+			return err
+		}
+
+		for i, feature := range level.Features {
+			_, err = db.Exec(`
+				INSERT INTO class_level_features
+				(class_slug, level, position, feature)
+				VALUES
+				(?, ?, ?, ?)
+			`, class.Slug, level.Level, i, feature)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, value := range level.Values {
+			_, err = db.Exec(`
+				INSERT INTO class_level_values
+				(class_slug, level, key, kind, number, dice, text)
+				VALUES
+				(?, ?, ?, ?, ?, ?, ?)
+			`, class.Slug, level.Level, value.Key, value.Kind, value.Number, value.Dice, value.Text)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
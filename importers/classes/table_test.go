@@ -0,0 +1,103 @@
+package classes
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"open5e_importer/open5e"
+)
+
+func TestParseClassTable(t *testing.T) {
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	classes, _ := convertJsonToClassImports(data, open5e.V1)
+	tables := map[string][]ClassLevel{}
+	for _, class := range classes {
+		levels, err := ParseClassTable(class.Table)
+		if err != nil {
+			t.Errorf("%s: %v", class.Slug, err)
+		}
+		if len(levels) != 20 {
+			t.Errorf("%s: %d levels, want 20", class.Slug, len(levels))
+		}
+		tables[class.Slug] = levels
+	}
+
+	tests := []struct {
+		class string
+		level int
+		want  ClassLevel
+	}{
+		{"barbarian", 9, ClassLevel{
+			Level: 9, ProficiencyBonus: 4, Features: []string{"Brutal Critical (1 die)"},
+			Values: []ClassLevelValue{
+				{Key: "rages", Kind: "number", Number: 4, Text: "4"},
+				{Key: "rage_damage", Kind: "number", Number: 3, Text: "+3"},
+			},
+		}},
+		{"barbarian", 20, ClassLevel{
+			Level: 20, ProficiencyBonus: 6, Features: []string{"Primal Champion"},
+			Values: []ClassLevelValue{
+				{Key: "rages", Kind: "unlimited", Text: "Unlimited"},
+				{Key: "rage_damage", Kind: "number", Number: 4, Text: "+4"},
+			},
+		}},
+		{"monk", 2, ClassLevel{
+			Level: 2, ProficiencyBonus: 2, Features: []string{"Ki", "Unarmored Movement"},
+			Values: []ClassLevelValue{
+				{Key: "martial_arts", Kind: "dice", Dice: "1d4", Text: "1d4"},
+				{Key: "ki_points", Kind: "number", Number: 2, Text: "2"},
+				{Key: "unarmored_movement", Kind: "feet", Number: 10, Text: "+10 ft."},
+			},
+		}},
+		{"paladin", 2, ClassLevel{
+			Level: 2, ProficiencyBonus: 2, Features: []string{"Fighting Style", "Spellcasting", "Divine Smite"},
+			Values: []ClassLevelValue{
+				{Key: "spell_slots_1", Kind: "number", Number: 2, Text: "2"},
+			},
+		}},
+		{"warlock", 2, ClassLevel{
+			Level: 2, ProficiencyBonus: 2, Features: []string{"Eldritch Invocations"},
+			Values: []ClassLevelValue{
+				{Key: "cantrips_known", Kind: "number", Number: 2, Text: "2"},
+				{Key: "spells_known", Kind: "number", Number: 3, Text: "3"},
+				{Key: "spell_slots", Kind: "number", Number: 2, Text: "2"},
+				{Key: "slot_level", Kind: "number", Number: 1, Text: "1st"},
+				{Key: "invocations_known", Kind: "number", Number: 2, Text: "2"},
+			},
+		}},
+		{"cleric", 2, ClassLevel{
+			Level: 2, ProficiencyBonus: 2, Features: []string{"Channel Divinity (1/rest)", "Divine Domain Feature"},
+			Values: []ClassLevelValue{
+				{Key: "cantrips_known", Kind: "number", Number: 3, Text: "3"},
+				{Key: "spell_slots_1", Kind: "number", Number: 3, Text: "3"},
+			},
+		}},
+	}
+	for _, test := range tests {
+		levels := tables[test.class]
+		if len(levels) < test.level {
+			continue
+		}
+		if got := levels[test.level-1]; !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s level %d:\ngot  %+v\nwant %+v", test.class, test.level, got, test.want)
+		}
+	}
+}
+
+func TestParseClassTableErrors(t *testing.T) {
+	if levels, err := ParseClassTable(""); levels != nil || err != nil {
+		t.Errorf("empty table = %v, %v", levels, err)
+	}
+	if _, err := ParseClassTable("| Rank | Features |\n|---|---|\n| 1 | Rage |"); err == nil {
+		t.Error("expected an error for a table without a Level column")
+	}
+	// the levels before a row which isn't understood aren't kept
+	levels, err := ParseClassTable("| Level | Features |\n|---|---|\n| 1st | Rage |\n| Second | Reckless Attack |")
+	if levels != nil || err == nil {
+		t.Errorf("table with a level not understood = %v, %v", levels, err)
+	}
+}
@@ -0,0 +1,113 @@
+package classes
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"open5e_importer/open5e"
+)
+
+// the v2 API breaks a class into a list of typed features, and serves
+// subclasses from the same endpoint as classes.
+type classV2 struct {
+	Key        string        `json:"key"`
+	Name       string        `json:"name"`
+	Document   open5e.Named  `json:"document"`
+	SubclassOf *open5e.Named `json:"subclass_of"`
+	HitDice    string        `json:"hit_dice"`
+	HitPoints  struct {
+		HitPointsAtFirstLevel   string `json:"hit_points_at_1st_level"`
+		HitPointsAtHigherLevels string `json:"hit_points_at_higher_levels"`
+	} `json:"hit_points"`
+	SavingThrows []open5e.Named   `json:"saving_throws"`
+	Features     []classFeatureV2 `json:"features"`
+}
+
+type classFeatureV2 struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Desc        string `json:"desc"`
+	FeatureType string `json:"feature_type"`
+}
+
+func decodeClassV2(raw json.RawMessage) (ClassImport, error) {
+	var v2 classV2
+	err := json.Unmarshal(raw, &v2)
+	if err != nil {
+		return ClassImport{}, err
+	}
+	if v2.SubclassOf != nil {
+		return decodeSubclassV2(v2), nil
+	}
+
+	class := ClassImport{
+		Name:             v2.Name,
+		Slug:             v2.Key,
+		HitDice:          hitDiceV2(v2.HitDice),
+		HpAtFirstLevel:   v2.HitPoints.HitPointsAtFirstLevel,
+		HpAtHigherLevels: v2.HitPoints.HitPointsAtHigherLevels,
+		DocumentSlug:     v2.Document.Key,
+	}
+
+	var savingThrows []string
+	for _, save := range v2.SavingThrows {
+		savingThrows = append(savingThrows, save.Name)
+	}
+	class.ProficienciesSavingThrows = strings.Join(savingThrows, ", ")
+
+	// features are rendered back into the markdown headings v1 uses for
+	// the class description
+	var description []string
+	for _, feature := range v2.Features {
+		switch feature.FeatureType {
+		case "PROFICIENCIES":
+			switch strings.ToLower(feature.Name) {
+			case "armor":
+				class.ProficienciesArmor = feature.Desc
+			case "weapons":
+				class.ProficienciesWeapons = feature.Desc
+			case "tools":
+				class.ProficienciesTools = feature.Desc
+			case "skills":
+				class.ProficienciesSkills = feature.Desc
+			}
+		case "STARTING_EQUIPMENT":
+			class.Equipment = feature.Desc
+		case "CLASS_LEVEL_FEATURE":
+			description = append(description, fmt.Sprintf("### %s \n \n%s", feature.Name, feature.Desc))
+		}
+	}
+	class.Description = strings.Join(description, " \n \n")
+
+	return class, nil
+}
+
+// v2 gives the hit die as "D12", v1 as "1d12"
+func hitDiceV2(hitDice string) string {
+	if hitDice == "" {
+		return ""
+	}
+	return "1" + strings.ToLower(hitDice)
+}
+
+// decodeSubclassV2 renders a subclass's features back into the "#####"
+// headings v1 uses for archetype descriptions.  its "Overview" feature is
+// the text before the first heading.
+func decodeSubclassV2(v2 classV2) ClassImport {
+	var description []string
+	for _, feature := range v2.Features {
+		if strings.EqualFold(feature.Name, "overview") {
+			description = append(description, feature.Desc)
+			continue
+		}
+		description = append(description, fmt.Sprintf("##### %s \n \n%s", feature.Name, feature.Desc))
+	}
+	return ClassImport{
+		Name:         v2.Name,
+		Slug:         v2.Key,
+		Description:  strings.Join(description, " \n \n"),
+		DocumentSlug: v2.Document.Key,
+		SubclassOf:   v2.SubclassOf.Key,
+	}
+}
@@ -0,0 +1,88 @@
+package conditions
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"open5e_importer/open5e"
+)
+
+// Import fetches every condition from the Open5e API and writes them to db,
+// returning the number of conditions imported.
+func Import(db *sqlx.DB, client *open5e.Client, version open5e.Version) (int, error) {
+	return open5e.Import(client, conditionResource, version, func(page []ConditionImport) error {
+		return writeConditionsToDB(db, page)
+	})
+}
+
+type ConditionImport struct {
+	Name         string `json:"name" db:"name"`
+	Slug         string `json:"slug" db:"slug"`
+	Description  string `json:"desc" db:"description"`
+	DocumentSlug string `json:"document__slug" db:"document_slug"`
+}
+
+// json keys of v1 results whose ConditionImport field isn't named after them
+var conditionAliases = map[string]string{
+	"desc": "Description",
+}
+
+var conditionResource = open5e.Resource[ConditionImport]{
+	V1Path:   "conditions",
+	DecodeV1: open5e.DecodeV1[ConditionImport](conditionAliases),
+	V2Path:   "conditions",
+	DecodeV2: decodeConditionV2,
+}
+
+// writes the conditions to condition_imports, replacing the row of a
+// condition imported before by its slug.
+func writeConditionsToDB(db *sqlx.DB, conditions []ConditionImport) error {
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS condition_imports (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT,
+			slug TEXT UNIQUE,
+			description TEXT,
+			document_slug TEXT REFERENCES documents(slug)
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create condition_imports: %w", err)
+	}
+
+	for idx := range conditions {
+		query := `
+			INSERT INTO condition_imports (
+				name, slug, description,
+				document_slug
+			)
+			VALUES
+			(?, ?, ?, ?)
+			ON CONFLICT(slug) DO UPDATE SET
+				name = excluded.name,
+				description = excluded.description,
+				document_slug = excluded.document_slug
+		`
+		condition := conditions[idx]
+		_, err = db.Exec(query, condition.Name, condition.Slug, condition.Description,
+			condition.DocumentSlug)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func convertJsonToConditionImports(jsonData []byte, version open5e.Version) ([]ConditionImport, string) {
+	conditions, nextUrl, err := open5e.DecodePage(conditionResource, version, jsonData)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if nextUrl == "" {
+		return conditions, "DONE"
+	}
+	return conditions, nextUrl
+}
@@ -0,0 +1,75 @@
+package conditions
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/open5e"
+)
+
+func TestImportConditions(t *testing.T) {
+	err := os.Remove("../../sql_database/conditions_test.db")
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	db, err := sqlx.Open("sqlite3", "../../sql_database/conditions_test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conditions, _ := convertJsonToConditionImports(data, open5e.V1)
+	// importing twice replaces the rows rather than adding to them
+	for i := 0; i < 2; i++ {
+		if err := writeConditionsToDB(db, conditions); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var count int
+	if err := db.Get(&count, `SELECT COUNT(*) FROM condition_imports`); err != nil {
+		t.Fatal(err)
+	}
+	if count != 11 {
+		t.Errorf("%d rows in condition_imports, want 11", count)
+	}
+
+	// the description is kept as the markdown list it is served as
+	var description string
+	if err := db.Get(&description, `SELECT description FROM condition_imports WHERE slug = 'blinded'`); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(description, "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "* A blinded creature can't see") || !strings.HasPrefix(lines[1], "* Attack rolls") {
+		t.Errorf("unexpected blinded description %q", description)
+	}
+}
+
+func TestDecodeConditionsV2(t *testing.T) {
+	v1Data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2Data, err := ioutil.ReadFile("./test_data/testdata_v2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1Conditions, _ := convertJsonToConditionImports(v1Data, open5e.V1)
+	v2Conditions, _ := convertJsonToConditionImports(v2Data, open5e.V2)
+	if len(v2Conditions) != 4 {
+		t.Fatalf("expected 4 conditions, got %d", len(v2Conditions))
+	}
+	for i, v2 := range v2Conditions {
+		v1 := v1Conditions[i]
+		if v2.Slug != v1.Slug || v2.Name != v1.Name || v2.Description != v1.Description || v2.DocumentSlug != "srd" {
+			t.Errorf("unexpected condition %+v, expected %+v", v2, v1)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package conditions
+
+import (
+	"encoding/json"
+
+	"open5e_importer/open5e"
+)
+
+// v2 conditions may carry one description per game system; the first
+// one is kept.
+type conditionV2 struct {
+	Key          string       `json:"key"`
+	Name         string       `json:"name"`
+	Desc         string       `json:"desc"`
+	Document     open5e.Named `json:"document"`
+	Descriptions []struct {
+		Desc     string       `json:"desc"`
+		Document open5e.Named `json:"document"`
+	} `json:"descriptions"`
+}
+
+func decodeConditionV2(raw json.RawMessage) (ConditionImport, error) {
+	var v2 conditionV2
+	err := json.Unmarshal(raw, &v2)
+	if err != nil {
+		return ConditionImport{}, err
+	}
+
+	condition := ConditionImport{
+		Name:         v2.Name,
+		Slug:         v2.Key,
+		Description:  v2.Desc,
+		DocumentSlug: v2.Document.Key,
+	}
+	if condition.Description == "" && len(v2.Descriptions) > 0 {
+		condition.Description = v2.Descriptions[0].Desc
+		condition.DocumentSlug = v2.Descriptions[0].Document.Key
+	}
+	return condition, nil
+}
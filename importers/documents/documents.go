@@ -0,0 +1,106 @@
+package documents
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"open5e_importer/open5e"
+)
+
+// Import fetches every document from the Open5e API and writes them to db,
+// returning the number of documents imported.
+func Import(db *sqlx.DB, client *open5e.Client, version open5e.Version) (int, error) {
+	return open5e.Import(client, documentResource, version, func(page []DocumentImport) error {
+		return writeDocumentsToDB(db, page)
+	})
+}
+
+type DocumentImport struct {
+	Slug         string `json:"slug" db:"slug"`
+	Title        string `json:"title" db:"title"`
+	Description  string `json:"desc" db:"description"`
+	License      string `json:"license" db:"license"`
+	LicenseUrl   string `json:"license_url" db:"license_url"`
+	Author       string `json:"author" db:"author"`
+	Organization string `json:"organization" db:"organization"`
+	Version      string `json:"version" db:"version"`
+	Copyright    string `json:"copyright" db:"copyright"`
+	Url          string `json:"url" db:"url"`
+}
+
+// json keys of v1 results whose DocumentImport field isn't named after them
+var documentAliases = map[string]string{
+	"desc": "Description",
+}
+
+var documentResource = open5e.Resource[DocumentImport]{
+	V1Path:   "documents",
+	DecodeV1: open5e.DecodeV1[DocumentImport](documentAliases),
+	V2Path:   "documents",
+	DecodeV2: decodeDocumentV2,
+}
+
+func writeDocumentsToDB(db *sqlx.DB, documents []DocumentImport) error {
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS documents (
+			slug TEXT PRIMARY KEY,
+			title TEXT,
+			description TEXT,
+			license TEXT,
+			license_url TEXT,
+			author TEXT,
+			organization TEXT,
+			version TEXT,
+			copyright TEXT,
+			url TEXT
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create documents: %w", err)
+	}
+
+	for idx := range documents {
+		// rows in the *_imports tables reference documents by slug, so
+		// re-importing updates the row in place rather than replacing it
+		query := `
+			INSERT INTO documents (
+				slug, title, description, license, license_url,
+				author, organization, version, copyright, url
+			)
+			VALUES
+			(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(slug) DO UPDATE SET
+				title = excluded.title,
+				description = excluded.description,
+				license = excluded.license,
+				license_url = excluded.license_url,
+				author = excluded.author,
+				organization = excluded.organization,
+				version = excluded.version,
+				copyright = excluded.copyright,
+				url = excluded.url
+		`
+		document := documents[idx]
+		_, err = db.Exec(query, document.Slug, document.Title, document.Description,
+			document.License, document.LicenseUrl, document.Author, document.Organization,
+			document.Version, document.Copyright, document.Url)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func convertJsonToDocumentImports(jsonData []byte, version open5e.Version) ([]DocumentImport, string) {
+	documents, nextUrl, err := open5e.DecodePage(documentResource, version, jsonData)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if nextUrl == "" {
+		return documents, "DONE"
+	}
+	return documents, nextUrl
+}
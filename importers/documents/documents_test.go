@@ -0,0 +1,67 @@
+package documents
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/open5e"
+)
+
+func TestImportDocuments(t *testing.T) {
+	err := os.Remove("../../sql_database/documents_test.db")
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	db, err := sqlx.Open("sqlite3", "../../sql_database/documents_test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	documents, _ := convertJsonToDocumentImports(data, open5e.V1)
+	// a document imported again is updated in place, so the rows which
+	// reference it by slug keep pointing at it
+	if err := writeDocumentsToDB(db, documents); err != nil {
+		t.Fatal(err)
+	}
+	documents[0].Title = "Renamed"
+	if err := writeDocumentsToDB(db, documents); err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	if err := db.Get(&count, `SELECT COUNT(*) FROM documents`); err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 {
+		t.Errorf("%d rows in documents, want 4", count)
+	}
+	var title string
+	if err := db.Get(&title, `SELECT title FROM documents WHERE slug = ?`, documents[0].Slug); err != nil {
+		t.Fatal(err)
+	}
+	if title != "Renamed" {
+		t.Errorf("%s title %q after its re-import, want %q", documents[0].Slug, title, "Renamed")
+	}
+}
+
+func TestDecodeDocumentsV2(t *testing.T) {
+	data, err := ioutil.ReadFile("./test_data/testdata_v2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	documents, _ := convertJsonToDocumentImports(data, open5e.V2)
+	if len(documents) != 2 {
+		t.Fatalf("expected 2 documents, got %d", len(documents))
+	}
+	srd := documents[0]
+	if srd.Slug != "srd" || srd.Title != "5e Core Rules" || srd.License != "Open Gaming License" || srd.Organization != "Wizards of the Coast™" {
+		t.Errorf("unexpected document %+v", srd)
+	}
+}
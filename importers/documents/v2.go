@@ -0,0 +1,40 @@
+package documents
+
+import (
+	"encoding/json"
+
+	"open5e_importer/open5e"
+)
+
+// the v2 API keys documents by a short key, names the publisher rather
+// than the organization, and lists licenses instead of naming one.
+type documentV2 struct {
+	Key       string         `json:"key"`
+	Name      string         `json:"name"`
+	Desc      string         `json:"desc"`
+	Author    string         `json:"author"`
+	Publisher open5e.Named   `json:"publisher"`
+	Licenses  []open5e.Named `json:"licenses"`
+	Permalink string         `json:"permalink"`
+}
+
+func decodeDocumentV2(raw json.RawMessage) (DocumentImport, error) {
+	var v2 documentV2
+	err := json.Unmarshal(raw, &v2)
+	if err != nil {
+		return DocumentImport{}, err
+	}
+
+	document := DocumentImport{
+		Slug:         v2.Key,
+		Title:        v2.Name,
+		Description:  v2.Desc,
+		Author:       v2.Author,
+		Organization: v2.Publisher.Name,
+		Url:          v2.Permalink,
+	}
+	if len(v2.Licenses) > 0 {
+		document.License = v2.Licenses[0].Name
+	}
+	return document, nil
+}
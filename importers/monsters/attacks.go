@@ -0,0 +1,282 @@
+package monsters
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Attack is the structured form of an action description such as
+// "Melee Weapon Attack: +9 to hit, reach 10 ft., one target. Hit: 12
+// (2d6 + 5) bludgeoning damage plus 4 (1d8) acid damage".
+type Attack struct {
+	Kind      string // "melee weapon", "ranged spell", "melee or ranged weapon", ...
+	ToHit     int32
+	Reach     int32 // in feet, 0 for ranged attacks
+	Range     int32 // normal range in feet, 0 for melee attacks
+	LongRange int32 // 0 when the attack has no long range
+	Targets   string
+	Damage    []Damage
+}
+
+type Damage struct {
+	Average int32
+	Dice    string // e.g. "2d6", empty for flat damage such as "1 piercing damage"
+	Bonus   int32
+	Type    string
+}
+
+// ErrNotAttack is returned by ParseAttack for descriptions which don't
+// start with an attack, as opposed to attacks it fails to parse.
+var ErrNotAttack = errors.New("not an attack")
+
+var damageTypes = map[string]bool{
+	"acid": true, "bludgeoning": true, "cold": true, "fire": true, "force": true,
+	"lightning": true, "necrotic": true, "piercing": true, "poison": true,
+	"psychic": true, "radiant": true, "slashing": true, "thunder": true,
+}
+
+var (
+	attackHeader  = regexp.MustCompile(`^(Melee or Ranged|Melee|Ranged) (Weapon|Spell) Attack:\s*([+-])\s*(\d+) to hit(?:\s*\([^)]*\))?`)
+	attackReach   = regexp.MustCompile(`^reach (\d+) ?(?:ft\b\.?|feet|\.?')`)
+	attackRange   = regexp.MustCompile(`^(?:or )?(?:range )?(\d+)/(\d+) ?(?:ft\b\.?|feet|\.?')|^(?:or )?range (\d+) ?(?:ft\b\.?|feet|\.?')`)
+	attackBare    = regexp.MustCompile(`^(\d+) ?(?:ft\b\.?|feet|\.?')`)
+	attackTargets = regexp.MustCompile(`^((?:one|two|three|four|five|six|\d+|up to \w+|each|all|any)\b[^.,]*)`)
+	attackHit     = regexp.MustCompile(`^[.,\s]*(?:Hit:\s*)?`)
+	damageFirst   = regexp.MustCompile(`^(\d+)(?:\s*\((\d+d\d+)\s*(?:([+\-–])\s*(\d+))?\))?(?:\s+([a-z]+))?`)
+	damageMore    = regexp.MustCompile(`^,?\s*(?:plus|\+|and)\s+(\d+)\s*\((\d+d\d+)\s*(?:([+\-–])\s*(\d+))?\)\s+([a-z]+)`)
+	damageSuffix  = regexp.MustCompile(`^\s+damage`)
+)
+
+// ParseAttack parses the attack at the start of an action description.
+// descriptions which don't start with an attack return ErrNotAttack.
+func ParseAttack(desc string) (Attack, error) {
+	var attack Attack
+	header := attackHeader.FindStringSubmatch(desc)
+	if header == nil {
+		return attack, ErrNotAttack
+	}
+	attack.Kind = strings.ToLower(header[1] + " " + header[2])
+	attack.ToHit = atoi32(header[4])
+	if header[3] == "-" {
+		attack.ToHit = -attack.ToHit
+	}
+	rest := desc[len(header[0]):]
+
+	rest = skipSeparators(rest)
+	distance := false
+	if m := attackReach.FindStringSubmatch(rest); m != nil {
+		attack.Reach = atoi32(m[1])
+		distance = true
+		rest = skipSeparators(rest[len(m[0]):])
+	}
+	if m := attackRange.FindStringSubmatch(rest); m != nil {
+		if m[1] != "" {
+			attack.Range, attack.LongRange = atoi32(m[1]), atoi32(m[2])
+		} else {
+			attack.Range = atoi32(m[3])
+		}
+		distance = true
+		rest = skipSeparators(rest[len(m[0]):])
+	}
+	if !distance {
+		// some sources leave out "reach" and "range", e.g. "+5 to hit, 5 ft."
+		m := attackBare.FindStringSubmatch(rest)
+		if m == nil {
+			return attack, fmt.Errorf("no reach or range")
+		}
+		if strings.HasPrefix(attack.Kind, "melee") {
+			attack.Reach = atoi32(m[1])
+		} else {
+			attack.Range = atoi32(m[1])
+		}
+		rest = skipSeparators(rest[len(m[0]):])
+	}
+
+	// the targets run up to "Hit:" when there is one, since they may
+	// contain commas, e.g. "one creature that is grappled, incapacitated,
+	// or restrained"
+	if hit := strings.Index(rest, "Hit:"); hit >= 0 {
+		attack.Targets = strings.TrimRight(rest[:hit], ",. ")
+		rest = rest[hit:]
+	} else if m := attackTargets.FindStringSubmatch(rest); m != nil {
+		attack.Targets = strings.TrimSpace(m[1])
+		rest = rest[len(m[0]):]
+	}
+	rest = rest[len(attackHit.FindString(rest)):]
+
+	// attacks whose hit is purely an effect, e.g. "Hit: The target is
+	// grappled (escape DC 13).", have no damage
+	m := damageFirst.FindStringSubmatch(rest)
+	if m == nil {
+		return attack, nil
+	}
+	if m[5] != "" && !damageTypes[m[5]] {
+		// the type is sometimes left out, e.g. "Hit: 14 (2d8 + 5)."
+		m[0], m[5] = strings.TrimSuffix(m[0], m[5]), ""
+	}
+	if m[2] == "" && m[5] == "" {
+		return attack, fmt.Errorf("unparsed damage")
+	}
+	attack.Damage = append(attack.Damage, damage(m))
+	rest = trimDamageSuffix(rest[len(m[0]):])
+	for {
+		m := damageMore.FindStringSubmatch(rest)
+		if m == nil || !damageTypes[m[5]] {
+			break
+		}
+		attack.Damage = append(attack.Damage, damage(m))
+		rest = trimDamageSuffix(rest[len(m[0]):])
+	}
+
+	return attack, nil
+}
+
+func damage(m []string) Damage {
+	d := Damage{Average: atoi32(m[1]), Dice: m[2], Bonus: atoi32(m[4]), Type: m[5]}
+	if m[3] == "-" || m[3] == "–" {
+		d.Bonus = -d.Bonus
+	}
+	return d
+}
+
+func skipSeparators(s string) string {
+	return strings.TrimLeft(s, ", ")
+}
+
+func trimDamageSuffix(s string) string {
+	return s[len(damageSuffix.FindString(s)):]
+}
+
+func atoi32(s string) int32 {
+	i, _ := strconv.Atoi(s)
+	return int32(i)
+}
+
+// monsterAction is a single entry from one of a monster's action lists.
+type monsterAction struct {
+	Kind string // "action", "bonus_action", "reaction", "legendary_action" or "special_ability"
+	Name string
+	Desc string
+
+	// v1 also gives the first attack's numbers, which monster_actions keeps
+	AttackBonus *int32
+	DamageDice  string
+	DamageBonus *int32
+}
+
+// monsterActions flattens a monster's actions, bonus actions, reactions,
+// legendary actions and special abilities into one list, in that order.
+func monsterActions(monster MonsterImport) []monsterAction {
+	var actions []monsterAction
+	for _, list := range []struct {
+		kind    string
+		entries []interface{}
+	}{
+		{"action", monster.Actions},
+		{"bonus_action", monster.BonusActions},
+		{"reaction", monster.Reactions},
+		{"legendary_action", monster.LegendaryActions},
+	} {
+		for _, entry := range list.entries {
+			obj, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			actions = append(actions, newMonsterAction(list.kind, obj))
+		}
+	}
+	for _, obj := range monster.SpecialAbilities {
+		actions = append(actions, newMonsterAction("special_ability", obj))
+	}
+	return actions
+}
+
+func newMonsterAction(kind string, obj map[string]interface{}) monsterAction {
+	action := monsterAction{Kind: kind}
+	action.Name, _ = obj["name"].(string)
+	action.Desc, _ = obj["desc"].(string)
+	action.DamageDice, _ = obj["damage_dice"].(string)
+	if bonus, ok := obj["attack_bonus"].(float64); ok {
+		action.AttackBonus = new(int32)
+		*action.AttackBonus = int32(bonus)
+	}
+	if bonus, ok := obj["damage_bonus"].(float64); ok {
+		action.DamageBonus = new(int32)
+		*action.DamageBonus = int32(bonus)
+	}
+	return action
+}
+
+func createMonsterAttackTables(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS monster_attacks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			monster_slug TEXT,
+			action_kind TEXT,
+			action_name TEXT,
+			kind TEXT,
+			to_hit INTEGER,
+			reach INTEGER,
+			range INTEGER,
+			long_range INTEGER,
+			targets TEXT
+		);
+		CREATE TABLE IF NOT EXISTS monster_attack_damage (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			attack_id INTEGER REFERENCES monster_attacks(id),
+			position INTEGER,
+			average INTEGER,
+			dice TEXT,
+			bonus INTEGER,
+			damage_type TEXT
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create monster_attacks: %w", err)
+	}
+	return nil
+}
+
+// writes a row to monster_attacks for every action of the monster which
+// parses as an attack, with its damage components in monster_attack_damage.
+func writeMonsterAttacksToDB(db sqlx.Execer, monster MonsterImport) error {
+	for _, action := range monsterActions(monster) {
+		attack, err := ParseAttack(action.Desc)
+		if err != nil {
+			continue
+		}
+
+		res, err := db.Exec(`
+			INSERT INTO monster_attacks
+			(monster_slug, action_kind, action_name, kind, to_hit, reach, range, long_range, targets)
+			VALUES
+			(?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, monster.Slug, action.Kind, action.Name, attack.Kind, attack.ToHit, attack.Reach, attack.Range,
+			attack.LongRange, attack.Targets)
+		if err != nil {
+			return err
+		}
+		attackId, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		for i, damage := range attack.Damage {
+			_, err = db.Exec(`
+				INSERT INTO monster_attack_damage
+				(attack_id, position, average, dice, bonus, damage_type)
+				VALUES
+				(?, ?, ?, ?, ?, ?)
+			`, attackId, i, damage.Average, damage.Dice, damage.Bonus, damage.Type)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package monsters
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseAttack(t *testing.T) {
+	cases := []struct {
+		desc   string
+		attack Attack
+	}{
+		{
+			"Melee Weapon Attack: +9 to hit, reach 10 ft., one target. Hit: 12 (2d6 + 5) bludgeoning damage plus 4 (1d8) acid damage.",
+			Attack{Kind: "melee weapon", ToHit: 9, Reach: 10, Targets: "one target", Damage: []Damage{
+				{Average: 12, Dice: "2d6", Bonus: 5, Type: "bludgeoning"},
+				{Average: 4, Dice: "1d8", Type: "acid"},
+			}},
+		},
+		{
+			"Ranged Weapon Attack: +3 to hit, range 80/320 ft., one target. Hit: 5 (1d8 + 1) piercing damage.",
+			Attack{Kind: "ranged weapon", ToHit: 3, Range: 80, LongRange: 320, Targets: "one target", Damage: []Damage{
+				{Average: 5, Dice: "1d8", Bonus: 1, Type: "piercing"},
+			}},
+		},
+		{
+			"Melee or Ranged Weapon Attack: +6 to hit, reach 5 ft. or range 20/60 ft., one target. Hit: 11 (2d6 + 4) piercing damage.",
+			Attack{Kind: "melee or ranged weapon", ToHit: 6, Reach: 5, Range: 20, LongRange: 60, Targets: "one target", Damage: []Damage{
+				{Average: 11, Dice: "2d6", Bonus: 4, Type: "piercing"},
+			}},
+		},
+		{
+			"Ranged Spell Attack: +7 to hit  range 120 ft.  one target. Hit: 21 (6d6) fire damage.",
+			Attack{Kind: "ranged spell", ToHit: 7, Range: 120, Targets: "one target", Damage: []Damage{
+				{Average: 21, Dice: "6d6", Type: "fire"},
+			}},
+		},
+		{
+			"Melee Weapon Attack: +4 to hit, 5 ft., one target, 9 (2d6+2) piercing damage.",
+			Attack{Kind: "melee weapon", ToHit: 4, Reach: 5, Targets: "one target", Damage: []Damage{
+				{Average: 9, Dice: "2d6", Bonus: 2, Type: "piercing"},
+			}},
+		},
+		{
+			"Melee Weapon Attack: +4 to hit, reach 5 ft., one target. Hit: 9 (2d6 + 2) piercing damage + 3 (1d6) fire damage.",
+			Attack{Kind: "melee weapon", ToHit: 4, Reach: 5, Targets: "one target", Damage: []Damage{
+				{Average: 9, Dice: "2d6", Bonus: 2, Type: "piercing"},
+				{Average: 3, Dice: "1d6", Type: "fire"},
+			}},
+		},
+		{
+			"Melee Weapon Attack: +2 to hit, reach 5 ft., one creature. Hit: 1 piercing damage.",
+			Attack{Kind: "melee weapon", ToHit: 2, Reach: 5, Targets: "one creature", Damage: []Damage{
+				{Average: 1, Type: "piercing"},
+			}},
+		},
+	}
+
+	for _, c := range cases {
+		attack, err := ParseAttack(c.desc)
+		if err != nil {
+			t.Errorf("could not parse %q: %v", c.desc, err)
+			continue
+		}
+		if !reflect.DeepEqual(attack, c.attack) {
+			t.Errorf("parsed %q as\n%+v\nexpected\n%+v", c.desc, attack, c.attack)
+		}
+	}
+
+	_, err := ParseAttack("The aboleth makes three tentacle attacks.")
+	if !errors.Is(err, ErrNotAttack) {
+		t.Errorf("expected ErrNotAttack, got %v", err)
+	}
+}
+
+// actions.txt is the dump of every action description written by
+// examine_actions.  nearly all of the attacks in it should parse.
+func TestParseAttackCoverage(t *testing.T) {
+	file, err := os.Open("./examine_actions/actions.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	attacks, parsed := 0, 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		_, err := ParseAttack(scanner.Text())
+		if errors.Is(err, ErrNotAttack) {
+			continue
+		}
+		attacks++
+		if err == nil {
+			parsed++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if attacks < 4000 || float64(parsed)/float64(attacks) < 0.99 {
+		t.Errorf("parsed %d of %d attacks", parsed, attacks)
+	}
+}
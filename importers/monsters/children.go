@@ -0,0 +1,164 @@
+package monsters
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// orNull is n, or NULL for a number which isn't given.
+func orNull(n int32) interface{} {
+	if n == 0 {
+		return nil
+	}
+	return n
+}
+
+// textOrNull is s, or NULL for text which isn't given.
+func textOrNull(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func createMonsterChildTables(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS monster_actions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			monster_slug TEXT,
+			kind TEXT,
+			position INTEGER,
+			name TEXT,
+			description TEXT,
+			attack_bonus INTEGER,
+			damage_dice TEXT,
+			damage_bonus INTEGER,
+			display_name TEXT,
+			usage_kind TEXT,
+			uses INTEGER,
+			recharge_min INTEGER,
+			recharge_max INTEGER,
+			rest TEXT,
+			legendary_cost INTEGER
+		);
+		CREATE TABLE IF NOT EXISTS monster_skills (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			monster_slug TEXT,
+			skill TEXT,
+			bonus INTEGER
+		);
+		CREATE TABLE IF NOT EXISTS monster_environments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			monster_slug TEXT,
+			environment TEXT
+		);
+		CREATE TABLE IF NOT EXISTS monster_saves (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			monster_slug TEXT,
+			ability TEXT,
+			bonus INTEGER
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create monster_actions: %w", err)
+	}
+	return nil
+}
+
+// writes the monster's action lists to monster_actions, one row per entry
+// numbered within its kind, and its skills, environments and listed saving
+// throws to monster_skills, monster_environments and monster_saves.
+// attack_bonus, damage_dice and damage_bonus are only given by v1.  every
+// action has a display name, its name without the usage limits, which are
+// in the usage columns.  a usage column the action's limits don't set is
+// NULL, so an action without limits has no uses rather than 0 of them.
+// legendary actions which don't say otherwise cost one action.
+func writeMonsterChildrenToDB(db sqlx.Execer, monster MonsterImport) error {
+	positions := map[string]int{}
+	for _, action := range monsterActions(monster) {
+		usage := ParseUsage(action.Name)
+		if action.Kind == "legendary_action" && usage.LegendaryCost == 0 {
+			usage.LegendaryCost = 1
+		}
+
+		_, err := db.Exec(`
+			INSERT INTO monster_actions
+			(monster_slug, kind, position, name, description, attack_bonus, damage_dice, damage_bonus,
+			display_name, usage_kind, uses, recharge_min, recharge_max, rest, legendary_cost)
+			VALUES
+			(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, monster.Slug, action.Kind, positions[action.Kind], action.Name, action.Desc,
+			action.AttackBonus, action.DamageDice, action.DamageBonus, usage.Name, textOrNull(usage.Kind()),
+			orNull(usage.PerDay), orNull(usage.RechargeMin), orNull(usage.RechargeMax),
+			textOrNull(usage.Rest), orNull(usage.LegendaryCost))
+		if err != nil {
+			return err
+		}
+		positions[action.Kind]++
+	}
+
+	var skills []string
+	for skill := range monster.Skills {
+		skills = append(skills, skill)
+	}
+	sort.Strings(skills)
+	for _, skill := range skills {
+		bonus, ok := monster.Skills[skill].(float64)
+		if !ok {
+			continue
+		}
+		_, err := db.Exec(`
+			INSERT INTO monster_skills
+			(monster_slug, skill, bonus)
+			VALUES
+			(?, ?, ?)
+		`, monster.Slug, skill, int32(bonus))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, environment := range monster.Environments {
+		name, ok := environment.(string)
+		if !ok {
+			continue
+		}
+		_, err := db.Exec(`
+			INSERT INTO monster_environments
+			(monster_slug, environment)
+			VALUES
+			(?, ?)
+		`, monster.Slug, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, save := range []struct {
+		ability string
+		bonus   *int32
+	}{
+		{"strength", monster.StrengthSave},
+		{"dexterity", monster.DexteritySave},
+		{"constitution", monster.ConstitutionSave},
+		{"intelligence", monster.IntelligenceSave},
+		{"wisdom", monster.WisdomSave},
+		{"charisma", monster.CharismaSave},
+	} {
+		if save.bonus == nil {
+			continue
+		}
+		_, err := db.Exec(`
+			INSERT INTO monster_saves
+			(monster_slug, ability, bonus)
+			VALUES
+			(?, ?, ?)
+		`, monster.Slug, save.ability, *save.bonus)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
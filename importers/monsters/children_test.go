@@ -0,0 +1,112 @@
+package monsters
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/open5e"
+)
+
+func TestReimportMonsters(t *testing.T) {
+	err := os.Remove("../../sql_database/children_test.db")
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	db, err := sqlx.Open("sqlite3", "../../sql_database/children_test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	monsters, _ := convertJsonToMonsterImports(data, open5e.V1)
+
+	tables := []string{"mob_imports", "monster_actions", "monster_skills", "monster_environments",
+		"monster_saves", "monster_attacks", "monster_attack_damage", "monster_spells", "monster_senses"}
+	counts := func() []int {
+		var counts []int
+		for _, table := range tables {
+			var count int
+			if err := db.Get(&count, "SELECT COUNT(*) FROM "+table); err != nil {
+				t.Fatal(err)
+			}
+			counts = append(counts, count)
+		}
+		return counts
+	}
+	if err := writeMonstersToDB(db, monsters); err != nil {
+		t.Fatal(err)
+	}
+	first := counts()
+	if err := writeMonstersToDB(db, monsters); err != nil {
+		t.Fatal(err)
+	}
+	if second := counts(); !reflect.DeepEqual(first, second) {
+		t.Errorf("re-import changed the row counts of %v from %v to %v", tables, first, second)
+	}
+	if first[0] != len(monsters) {
+		t.Errorf("%d monsters in mob_imports, want %d", first[0], len(monsters))
+	}
+
+	var kinds []string
+	err = db.Select(&kinds, `
+		SELECT kind || ' ' || position || ' ' || name FROM monster_actions
+		WHERE monster_slug = 'aboleth' AND kind != 'special_ability' ORDER BY id
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantKinds := []string{"action 0 Multiattack", "action 1 Tentacle", "action 2 Tail", "action 3 Enslave (3/day)",
+		"legendary_action 0 Detect", "legendary_action 1 Tail Swipe", "legendary_action 2 Psychic Drain (Costs 2 Actions)"}
+	if !reflect.DeepEqual(kinds, wantKinds) {
+		t.Errorf("aboleth actions = %q, want %q", kinds, wantKinds)
+	}
+
+	// the usage limits are on the action's own row
+	var usages []string
+	err = db.Select(&usages, `
+		SELECT display_name || ' ' || COALESCE(usage_kind, '-') || ' ' || COALESCE(uses, '-') || ' ' ||
+			COALESCE(legendary_cost, '-')
+		FROM monster_actions
+		WHERE monster_slug = 'aboleth' AND name IN ('Enslave (3/day)', 'Psychic Drain (Costs 2 Actions)') ORDER BY id
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Enslave per_day 3 -", "Psychic Drain - - 2"}; !reflect.DeepEqual(usages, want) {
+		t.Errorf("aboleth usages = %q, want %q", usages, want)
+	}
+
+	var saves []string
+	err = db.Select(&saves, `SELECT ability || ' ' || bonus FROM monster_saves WHERE monster_slug = 'aboleth' ORDER BY id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"constitution 6", "intelligence 8", "wisdom 6"}; !reflect.DeepEqual(saves, want) {
+		t.Errorf("aboleth saves = %q, want %q", saves, want)
+	}
+
+	var skills []string
+	err = db.Select(&skills, `SELECT skill || ' ' || bonus FROM monster_skills WHERE monster_slug = 'aboleth' ORDER BY id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"history 12", "perception 10"}; !reflect.DeepEqual(skills, want) {
+		t.Errorf("aboleth skills = %q, want %q", skills, want)
+	}
+
+	var environments int
+	err = db.Get(&environments, `SELECT COUNT(*) FROM monster_environments WHERE monster_slug = 'aboleth'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if environments != 5 {
+		t.Errorf("aboleth has %d environments, want 5", environments)
+	}
+}
@@ -0,0 +1,128 @@
+package monsters
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ChallengeRating is an exact challenge rating, Num/Den, so that 1/8,
+// 1/4 and 1/2 don't go through float rounding.
+type ChallengeRating struct {
+	Num int32
+	Den int32
+}
+
+// xpByRating is the experience a monster of each whole challenge rating
+// is worth.
+var xpByRating = []int32{
+	10, 200, 450, 700, 1100, 1800, 2300, 2900, 3900, 5000, 5900,
+	7200, 8400, 10000, 11500, 13000, 15000, 18000, 20000, 22000, 25000,
+	33000, 41000, 50000, 62000, 75000, 90000, 105000, 120000, 135000, 155000,
+}
+
+var xpByFraction = map[int32]int32{8: 25, 4: 50, 2: 100}
+
+// ParseChallengeRating reads the challenge_rating string, "0", "1/8", "1/4",
+// "1/2" or a whole number up to 30.
+func ParseChallengeRating(s string) (ChallengeRating, error) {
+	s = strings.TrimSpace(s)
+	num, den, fraction := strings.Cut(s, "/")
+	cr := ChallengeRating{Den: 1}
+	n, err := strconv.Atoi(num)
+	if err != nil {
+		return ChallengeRating{}, fmt.Errorf("bad challenge rating %q", s)
+	}
+	cr.Num = int32(n)
+	if fraction {
+		d, err := strconv.Atoi(den)
+		if err != nil || d == 0 {
+			return ChallengeRating{}, fmt.Errorf("bad challenge rating %q", s)
+		}
+		cr.Den = int32(d)
+	}
+	if !cr.valid() {
+		return ChallengeRating{}, fmt.Errorf("challenge rating %q is not on the challenge rating table", s)
+	}
+	return cr, nil
+}
+
+// ChallengeRatingFromFloat converts the cr float, for data which only
+// has that.
+func ChallengeRatingFromFloat(f float64) (ChallengeRating, error) {
+	for _, den := range []int32{1, 2, 4, 8} {
+		num := math.Round(f * float64(den))
+		if math.Abs(num/float64(den)-f) < 1e-6 {
+			cr := ChallengeRating{Num: int32(num), Den: den}
+			if cr.valid() {
+				return cr, nil
+			}
+		}
+	}
+	return ChallengeRating{}, fmt.Errorf("challenge rating %v is not on the challenge rating table", f)
+}
+
+func (cr ChallengeRating) valid() bool {
+	if cr.Den == 1 {
+		return cr.Num >= 0 && int(cr.Num) < len(xpByRating)
+	}
+	return cr.Num == 1 && xpByFraction[cr.Den] != 0
+}
+
+func (cr ChallengeRating) String() string {
+	if cr.Den == 1 {
+		return strconv.Itoa(int(cr.Num))
+	}
+	return fmt.Sprintf("%d/%d", cr.Num, cr.Den)
+}
+
+func (cr ChallengeRating) Float() float64 {
+	return float64(cr.Num) / float64(cr.Den)
+}
+
+func (cr ChallengeRating) XP() int32 {
+	if cr.Den != 1 {
+		return xpByFraction[cr.Den]
+	}
+	return xpByRating[cr.Num]
+}
+
+// ProficiencyBonus is +2 up to challenge 4 and goes up by one every four
+// challenge ratings after that.
+func (cr ChallengeRating) ProficiencyBonus() int32 {
+	if cr.Den != 1 || cr.Num < 5 {
+		return 2
+	}
+	return 2 + (cr.Num-1)/4
+}
+
+// Band groups challenge ratings by the tiers of play they suit: "low"
+// up to 4, "moderate" up to 10, "high" up to 16 and "very high" beyond.
+func (cr ChallengeRating) Band() string {
+	switch f := cr.Float(); {
+	case f <= 4:
+		return "low"
+	case f <= 10:
+		return "moderate"
+	case f <= 16:
+		return "high"
+	default:
+		return "very high"
+	}
+}
+
+// monsterChallengeRating reads the monster's exact challenge rating from
+// its challenge_rating string, falling back on cr when there isn't one,
+// and reports whether the two disagree.
+func monsterChallengeRating(monster MonsterImport) (ChallengeRating, bool, error) {
+	if monster.ChallengeRatingText == "" {
+		cr, err := ChallengeRatingFromFloat(float64(monster.ChallengeRating))
+		return cr, false, err
+	}
+	cr, err := ParseChallengeRating(monster.ChallengeRatingText)
+	if err != nil {
+		return cr, false, err
+	}
+	return cr, math.Abs(cr.Float()-float64(monster.ChallengeRating)) > 1e-6, nil
+}
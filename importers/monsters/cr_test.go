@@ -0,0 +1,49 @@
+package monsters
+
+import "testing"
+
+func TestChallengeRating(t *testing.T) {
+	cases := []struct {
+		s     string
+		f     float64
+		xp    int32
+		bonus int32
+		band  string
+	}{
+		{"0", 0, 10, 2, "low"},
+		{"1/8", 0.125, 25, 2, "low"},
+		{"1/4", 0.25, 50, 2, "low"},
+		{"1/2", 0.5, 100, 2, "low"},
+		{"4", 4, 1100, 2, "low"},
+		{"5", 5, 1800, 3, "moderate"},
+		{"10", 10, 5900, 4, "moderate"},
+		{"13", 13, 10000, 5, "high"},
+		{"17", 17, 18000, 6, "very high"},
+		{"24", 24, 62000, 7, "very high"},
+		{"30", 30, 155000, 9, "very high"},
+	}
+	for _, c := range cases {
+		cr, err := ParseChallengeRating(c.s)
+		if err != nil {
+			t.Errorf("ParseChallengeRating(%q): %v", c.s, err)
+			continue
+		}
+		if cr.String() != c.s || cr.Float() != c.f || cr.XP() != c.xp || cr.ProficiencyBonus() != c.bonus || cr.Band() != c.band {
+			t.Errorf("%q = %s, %v, %d XP, +%d, %q", c.s, cr, cr.Float(), cr.XP(), cr.ProficiencyBonus(), cr.Band())
+		}
+		if fromFloat, err := ChallengeRatingFromFloat(c.f); err != nil || fromFloat != cr {
+			t.Errorf("ChallengeRatingFromFloat(%v) = %s, %v", c.f, fromFloat, err)
+		}
+	}
+
+	for _, s := range []string{"", "1/3", "2/4", "31", "-1", "x"} {
+		if _, err := ParseChallengeRating(s); err == nil {
+			t.Errorf("ParseChallengeRating(%q) succeeded", s)
+		}
+	}
+
+	_, mismatch, err := monsterChallengeRating(MonsterImport{ChallengeRating: 0.5, ChallengeRatingText: "1/4"})
+	if err != nil || !mismatch {
+		t.Errorf("expected 1/4 and 0.5 to disagree, got %v, %v", mismatch, err)
+	}
+}
@@ -0,0 +1,154 @@
+package monsters
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DamageModifier is one damage type a monster resists, is immune or is
+// vulnerable to, e.g. "slashing from nonmagical attacks that aren't
+// silvered".  Note keeps the qualifier as written, since some, like "from
+// magic weapons wielded by good creatures", have no flag.  a clause with no
+// damage type, like "damage from spells", leaves DamageType empty.
+type DamageModifier struct {
+	DamageType    string
+	Nonmagical    bool
+	NotSilvered   bool
+	NotAdamantine bool
+	Note          string
+}
+
+var (
+	damageQualifier = regexp.MustCompile(`(?i)\s+(?:from|that|while|when|if|except)\b.*$|\s*\(.*$`)
+	damageWord      = regexp.MustCompile(`[a-z]+`)
+)
+
+// ParseDamageModifiers reads a damage_resistances, damage_immunities or
+// damage_vulnerabilities line such as "acid, cold; bludgeoning, piercing,
+// and slashing from nonmagical attacks that aren't silvered".  each clause
+// between semicolons shares its qualifier between its damage types.
+func ParseDamageModifiers(s string) []DamageModifier {
+	var modifiers []DamageModifier
+	for _, clause := range strings.Split(s, ";") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+
+		types := clause
+		var qualifier string
+		if loc := damageQualifier.FindStringIndex(clause); loc != nil {
+			types, qualifier = clause[:loc[0]], strings.TrimSpace(clause[loc[0]:])
+		}
+		lower := strings.ToLower(qualifier)
+		modifier := DamageModifier{
+			Nonmagical:    strings.Contains(lower, "nonmagical"),
+			NotSilvered:   strings.Contains(lower, "silvered"),
+			NotAdamantine: strings.Contains(lower, "adamantine"),
+			Note:          qualifier,
+		}
+
+		found := false
+		for _, word := range damageWord.FindAllString(strings.ToLower(types), -1) {
+			if damageTypes[word] {
+				found = true
+				modifier.DamageType = word
+				modifiers = append(modifiers, modifier)
+			}
+		}
+		if !found {
+			modifier.Note = clause
+			modifiers = append(modifiers, modifier)
+		}
+	}
+	return modifiers
+}
+
+// ParseConditionImmunities splits a condition_immunities line such as
+// "charmed, exhaustion, frightened" into conditions.  entries which aren't
+// one of the conditions are returned separately.
+func ParseConditionImmunities(s string) ([]string, []string) {
+	var conditions, unknown []string
+	for _, entry := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return r == ',' || r == ';' }) {
+		entry = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(entry), "and "))
+		switch {
+		case entry == "":
+		case contains(conditionNames, entry):
+			conditions = append(conditions, entry)
+		default:
+			unknown = append(unknown, entry)
+		}
+	}
+	return conditions, unknown
+}
+
+func createMonsterDefenseTables(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS monster_damage_modifiers (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			monster_slug TEXT,
+			modifier TEXT,
+			damage_type TEXT,
+			nonmagical BOOLEAN,
+			not_silvered BOOLEAN,
+			not_adamantine BOOLEAN,
+			note TEXT
+		);
+		CREATE TABLE IF NOT EXISTS monster_condition_immunities (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			monster_slug TEXT,
+			condition TEXT
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create monster_damage_modifiers: %w", err)
+	}
+	return nil
+}
+
+// writes the monster's resistances, immunities and vulnerabilities to
+// monster_damage_modifiers, with modifier "resistance", "immunity" or
+// "vulnerability", and its condition immunities to
+// monster_condition_immunities.
+func writeMonsterDefensesToDB(db sqlx.Execer, monster MonsterImport) error {
+	for _, list := range []struct {
+		modifier string
+		text     string
+	}{
+		{"resistance", monster.DamageResistances},
+		{"immunity", monster.DamageImmunities},
+		{"vulnerability", monster.DamageVulnerabilities},
+	} {
+		for _, m := range ParseDamageModifiers(list.text) {
+			_, err := db.Exec(`
+				INSERT INTO monster_damage_modifiers
+				(monster_slug, modifier, damage_type, nonmagical, not_silvered, not_adamantine, note)
+				VALUES
+				(?, ?, ?, ?, ?, ?, ?)
+			`, monster.Slug, list.modifier, m.DamageType, m.Nonmagical, m.NotSilvered, m.NotAdamantine, m.Note)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	conditions, unknown := ParseConditionImmunities(monster.ConditionImmunities)
+	if len(unknown) > 0 {
+		fmt.Printf("Condition immunities of %s not understood: %q\n", monster.Slug, unknown)
+	}
+	for _, condition := range conditions {
+		_, err := db.Exec(`
+			INSERT INTO monster_condition_immunities
+			(monster_slug, condition)
+			VALUES
+			(?, ?)
+		`, monster.Slug, condition)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
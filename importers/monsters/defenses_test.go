@@ -0,0 +1,49 @@
+package monsters
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDamageModifiers(t *testing.T) {
+	werewolf := "from nonmagical attacks that aren't silvered"
+	cases := []struct {
+		s         string
+		modifiers []DamageModifier
+	}{
+		{"", nil},
+		{"acid", []DamageModifier{{DamageType: "acid"}}},
+		{"bludgeoning, piercing, and slashing from nonmagical attacks that aren't silvered", []DamageModifier{
+			{DamageType: "bludgeoning", Nonmagical: true, NotSilvered: true, Note: werewolf},
+			{DamageType: "piercing", Nonmagical: true, NotSilvered: true, Note: werewolf},
+			{DamageType: "slashing", Nonmagical: true, NotSilvered: true, Note: werewolf},
+		}},
+		{"cold, fire; piercing from nonmagical attacks that aren't adamantine", []DamageModifier{
+			{DamageType: "cold"},
+			{DamageType: "fire"},
+			{DamageType: "piercing", Nonmagical: true, NotAdamantine: true, Note: "from nonmagical attacks that aren't adamantine"},
+		}},
+		{"damage from spells; psychic", []DamageModifier{
+			{Note: "damage from spells"},
+			{DamageType: "psychic"},
+		}},
+		{"piercing from magic weapons wielded by good creatures", []DamageModifier{
+			{DamageType: "piercing", Note: "from magic weapons wielded by good creatures"},
+		}},
+	}
+	for _, c := range cases {
+		if got := ParseDamageModifiers(c.s); !reflect.DeepEqual(got, c.modifiers) {
+			t.Errorf("ParseDamageModifiers(%q)\n got %+v\nwant %+v", c.s, got, c.modifiers)
+		}
+	}
+}
+
+func TestParseConditionImmunities(t *testing.T) {
+	conditions, unknown := ParseConditionImmunities("Charmed, exhaustion, frightened, and poisoned; unconscious, exhausted")
+	if want := []string{"charmed", "exhaustion", "frightened", "poisoned", "unconscious"}; !reflect.DeepEqual(conditions, want) {
+		t.Errorf("conditions = %q, want %q", conditions, want)
+	}
+	if want := []string{"exhausted"}; !reflect.DeepEqual(unknown, want) {
+		t.Errorf("unknown = %q, want %q", unknown, want)
+	}
+}
@@ -0,0 +1,305 @@
+package monsters
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/dice"
+)
+
+// derivedSource is what the derivation reads back from mob_imports,
+// monster_skills and monster_saves.
+type derivedSource struct {
+	Slug         string           `db:"slug"`
+	Strength     int32            `db:"strength"`
+	Dexterity    int32            `db:"dexterity"`
+	Constitution int32            `db:"constitution"`
+	Intelligence int32            `db:"intelligence"`
+	Wisdom       int32            `db:"wisdom"`
+	Charisma     int32            `db:"charisma"`
+	Perception   sql.NullInt32    `db:"perception_skill"`
+	HP           int32            `db:"hp"`
+	HitDice      string           `db:"hit_dice"`
+	Saves        map[string]int32 // the bonuses listed in monster_saves
+}
+
+// Derived holds the numbers worked out from a monster's stat block rather
+// than read from it.  Saves are the listed bonus, or the ability modifier
+// when the monster has none listed.  DamagePerRound assumes every attack
+// of the monster's best routine hits.
+type Derived struct {
+	Modifiers         [6]int32 // strength, dexterity, constitution, intelligence, wisdom, charisma
+	Saves             [6]int32
+	PassivePerception int32
+	AverageHP         int32
+	HitDiceCount      int32
+	HitDie            int32
+	HitDiceBonus      int32
+	DamagePerRound    float64
+	HPMismatch        bool // hit points aren't the hit dice average
+	ConMismatch       bool // the hit dice bonus isn't CON modifier per hit die
+}
+
+var abilityOrder = []string{"strength", "dexterity", "constitution", "intelligence", "wisdom", "charisma"}
+
+func abilityModifier(score int32) int32 {
+	return int32(math.Floor(float64(score-10) / 2))
+}
+
+// derive works out everything but DamagePerRound, which needs the
+// monster's attacks.
+func derive(m derivedSource) (Derived, error) {
+	var d Derived
+	scores := []int32{m.Strength, m.Dexterity, m.Constitution, m.Intelligence, m.Wisdom, m.Charisma}
+	for i, score := range scores {
+		d.Modifiers[i] = abilityModifier(score)
+		d.Saves[i] = d.Modifiers[i]
+		if save, ok := m.Saves[abilityOrder[i]]; ok {
+			d.Saves[i] = save
+		}
+	}
+
+	d.PassivePerception = 10 + d.Modifiers[4]
+	if m.Perception.Valid {
+		d.PassivePerception = 10 + m.Perception.Int32
+	}
+
+	expr, err := dice.Parse(m.HitDice)
+	if err != nil {
+		return d, err
+	}
+	d.AverageHP = int32(math.Floor(expr.Average()))
+	for _, term := range expr.Terms {
+		switch {
+		case term.Sides == 0 && term.Negative:
+			d.HitDiceBonus -= int32(term.Count)
+		case term.Sides == 0:
+			d.HitDiceBonus += int32(term.Count)
+		case d.HitDie == 0:
+			d.HitDiceCount, d.HitDie = int32(term.Count), int32(term.Sides)
+		}
+	}
+	d.HPMismatch = m.HP != d.AverageHP
+	d.ConMismatch = d.HitDiceBonus != d.HitDiceCount*d.Modifiers[2]
+	return d, nil
+}
+
+// routineStep is a row of monster_multiattack.
+type routineStep struct {
+	Option int32  `db:"option"`
+	Action string `db:"action_name"`
+	Count  int32  `db:"count"`
+	Attack string `db:"attack"`
+}
+
+// monsterAttack is an action's attack with its total average damage.
+type monsterAttack struct {
+	Action  string  `db:"action_name"`
+	Kind    string  `db:"kind"`
+	Average float64 `db:"average"`
+}
+
+// damagePerRound is the average damage of the monster's best multiattack
+// option, or of its best single attack when it has no multiattack.
+func damagePerRound(attacks []monsterAttack, routine []routineStep) float64 {
+	best := func(allowed string) float64 {
+		max := 0.0
+		for _, a := range attacks {
+			if allowed == "any" || strings.Contains(a.Kind, allowed) {
+				max = math.Max(max, a.Average)
+			}
+		}
+		return max
+	}
+	if len(routine) == 0 {
+		return best("any")
+	}
+
+	options := map[int32]float64{}
+	for _, step := range routine {
+		avg := 0.0
+		if step.Action == "" {
+			avg = best(step.Attack)
+		}
+		for _, a := range attacks {
+			if a.Action == step.Action && step.Action != "" {
+				avg = a.Average
+			}
+		}
+		options[step.Option] += float64(step.Count) * avg
+	}
+	dpr := 0.0
+	for _, total := range options {
+		dpr = math.Max(dpr, total)
+	}
+	return dpr
+}
+
+// monster_derived is created with the monster tables too, so a monster's
+// row can be removed when the monster is imported again.
+func createMonsterDerivedTable(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS monster_derived (
+			monster_slug TEXT PRIMARY KEY,
+			strength_mod INTEGER,
+			dexterity_mod INTEGER,
+			constitution_mod INTEGER,
+			intelligence_mod INTEGER,
+			wisdom_mod INTEGER,
+			charisma_mod INTEGER,
+			strength_save INTEGER,
+			dexterity_save INTEGER,
+			constitution_save INTEGER,
+			intelligence_save INTEGER,
+			wisdom_save INTEGER,
+			charisma_save INTEGER,
+			passive_perception INTEGER,
+			average_hp INTEGER,
+			hit_dice_count INTEGER,
+			hit_die INTEGER,
+			hit_dice_bonus INTEGER,
+			damage_per_round FLOAT,
+			hp_mismatch BOOLEAN,
+			con_mismatch BOOLEAN
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create monster_derived: %w", err)
+	}
+	return nil
+}
+
+// Derive fills monster_derived from the imported monsters, their attacks
+// and multiattack routines, replacing what an earlier run derived.  it
+// returns the number of monsters derived.
+func Derive(db *sqlx.DB) (int, error) {
+	err := createMonsterDerivedTable(db)
+	if err != nil {
+		return 0, err
+	}
+
+	var monsters []derivedSource
+	err = db.Select(&monsters, `
+		SELECT slug, strength, dexterity, constitution, intelligence, wisdom, charisma, hp, hit_dice,
+		(SELECT bonus FROM monster_skills s WHERE s.monster_slug = m.slug AND s.skill = 'perception') AS perception_skill
+		FROM mob_imports m
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	saves := map[string]map[string]int32{}
+	rows, err := db.Queryx(`SELECT monster_slug, ability, bonus FROM monster_saves`)
+	if err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var slug, ability string
+		var bonus int32
+		if err := rows.Scan(&slug, &ability, &bonus); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if saves[slug] == nil {
+			saves[slug] = map[string]int32{}
+		}
+		saves[slug][ability] = bonus
+	}
+	rows.Close()
+
+	// only attacks made as actions count towards a round's damage
+	attacks := map[string][]monsterAttack{}
+	rows, err = db.Queryx(`
+		SELECT a.monster_slug, a.action_name, a.kind, COALESCE(SUM(d.average), 0) AS average
+		FROM monster_attacks a
+		LEFT JOIN monster_attack_damage d ON d.attack_id = a.id
+		WHERE a.action_kind = 'action'
+		GROUP BY a.id
+	`)
+	if err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var slug string
+		var a monsterAttack
+		if err := rows.Scan(&slug, &a.Action, &a.Kind, &a.Average); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		attacks[slug] = append(attacks[slug], a)
+	}
+	rows.Close()
+
+	routines := map[string][]routineStep{}
+	rows, err = db.Queryx(`
+		SELECT monster_slug, option, action_name, count, attack
+		FROM monster_multiattack
+		ORDER BY monster_slug, option, position
+	`)
+	if err != nil {
+		return 0, err
+	}
+	for rows.Next() {
+		var slug string
+		var s routineStep
+		if err := rows.Scan(&slug, &s.Option, &s.Action, &s.Count, &s.Attack); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		routines[slug] = append(routines[slug], s)
+	}
+	rows.Close()
+
+	// the rows of an earlier run are replaced all at once, so a failure
+	// part way leaves them as they were
+	tx, err := db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+	_, err = tx.Exec(`DELETE FROM monster_derived`)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	count := 0
+	for _, m := range monsters {
+		m.Saves = saves[m.Slug]
+		// the columns derived from the hit dice are left NULL when they
+		// aren't understood
+		var averageHP, hitDiceCount, hitDie, hitDiceBonus, hpMismatch, conMismatch interface{}
+		d, err := derive(m)
+		if err != nil {
+			fmt.Printf("Hit dice of %s not understood: %v\n", m.Slug, err)
+		} else {
+			averageHP, hitDiceCount, hitDie, hitDiceBonus = d.AverageHP, d.HitDiceCount, d.HitDie, d.HitDiceBonus
+			hpMismatch, conMismatch = d.HPMismatch, d.ConMismatch
+		}
+		d.DamagePerRound = damagePerRound(attacks[m.Slug], routines[m.Slug])
+
+		_, err = tx.Exec(`
+			INSERT OR REPLACE INTO monster_derived
+			(monster_slug, strength_mod, dexterity_mod, constitution_mod, intelligence_mod, wisdom_mod,
+			charisma_mod, strength_save, dexterity_save, constitution_save, intelligence_save,
+			wisdom_save, charisma_save, passive_perception, average_hp, hit_dice_count, hit_die,
+			hit_dice_bonus, damage_per_round, hp_mismatch, con_mismatch)
+			VALUES
+			(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, m.Slug, d.Modifiers[0], d.Modifiers[1], d.Modifiers[2], d.Modifiers[3], d.Modifiers[4],
+			d.Modifiers[5], d.Saves[0], d.Saves[1], d.Saves[2], d.Saves[3], d.Saves[4], d.Saves[5],
+			d.PassivePerception, averageHP, hitDiceCount, hitDie, hitDiceBonus,
+			d.DamagePerRound, hpMismatch, conMismatch)
+		if err != nil {
+			tx.Rollback()
+			return 0, fmt.Errorf("%s: %w", m.Slug, err)
+		}
+		count++
+	}
+	err = tx.Commit()
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -0,0 +1,126 @@
+package monsters
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/open5e"
+)
+
+func TestDerive(t *testing.T) {
+	// a database of its own, so TestImportMonsters removing its database
+	// can't pull it out from under us
+	err := os.Remove("../../sql_database/derived_test.db")
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	db, err := sqlx.Open("sqlite3", "../../sql_database/derived_test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	monsters, _ := convertJsonToMonsterImports(data, open5e.V1)
+	if err := writeMonstersToDB(db, monsters); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := Derive(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != len(monsters) {
+		t.Errorf("derived %d monsters, want %d", count, len(monsters))
+	}
+
+	var aboleth struct {
+		ConMod         int32   `db:"constitution_mod"`
+		DexSave        int32   `db:"dexterity_save"`
+		ConSave        int32   `db:"constitution_save"`
+		Passive        int32   `db:"passive_perception"`
+		AverageHP      int32   `db:"average_hp"`
+		DamagePerRound float64 `db:"damage_per_round"`
+		HPMismatch     bool    `db:"hp_mismatch"`
+		ConMismatch    bool    `db:"con_mismatch"`
+	}
+	err = db.Get(&aboleth, `
+		SELECT constitution_mod, dexterity_save, constitution_save, passive_perception, average_hp,
+		damage_per_round, hp_mismatch, con_mismatch
+		FROM monster_derived WHERE monster_slug = 'aboleth'
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 18d10+36 hit dice, three 12 damage tentacle attacks, DEX 9 with no
+	// listed save and CON 15 with +6
+	if aboleth.ConMod != 2 || aboleth.DexSave != -1 || aboleth.ConSave != 6 || aboleth.Passive != 20 ||
+		aboleth.AverageHP != 135 || aboleth.DamagePerRound != 36 || aboleth.HPMismatch || aboleth.ConMismatch {
+		t.Errorf("unexpected aboleth %+v", aboleth)
+	}
+
+	// hit dice which aren't understood leave the columns derived from them
+	// NULL, rather than claiming 0 hit points
+	if _, err := db.Exec(`UPDATE mob_imports SET hit_dice = 'lots' WHERE slug = 'aboleth'`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Derive(db); err != nil {
+		t.Fatal(err)
+	}
+	var nulls, conMod int
+	err = db.QueryRow(`
+		SELECT (average_hp IS NULL) + (hit_dice_count IS NULL) + (hit_die IS NULL) +
+			(hit_dice_bonus IS NULL) + (hp_mismatch IS NULL) + (con_mismatch IS NULL), constitution_mod
+		FROM monster_derived WHERE monster_slug = 'aboleth'
+	`).Scan(&nulls, &conMod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nulls != 6 || conMod != 2 {
+		t.Errorf("%d of the hit dice columns are NULL and constitution_mod is %d, want 6 and 2", nulls, conMod)
+	}
+
+	// importing a monster again drops what was derived from its old rows
+	if err := writeMonstersToDB(db, monsters[:1]); err != nil {
+		t.Fatal(err)
+	}
+	var derived int
+	if err := db.Get(&derived, `SELECT COUNT(*) FROM monster_derived WHERE monster_slug = ?`, monsters[0].Slug); err != nil {
+		t.Fatal(err)
+	}
+	if derived != 0 {
+		t.Errorf("%s still has %d monster_derived rows after its re-import", monsters[0].Slug, derived)
+	}
+}
+
+func TestDerivedMismatches(t *testing.T) {
+	d, err := derive(derivedSource{Constitution: 14, HP: 30, HitDice: "4d8+4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.AverageHP != 22 || !d.HPMismatch || !d.ConMismatch || d.HitDiceCount != 4 || d.HitDie != 8 {
+		t.Errorf("unexpected %+v", d)
+	}
+}
+
+func TestDamagePerRound(t *testing.T) {
+	attacks := []monsterAttack{
+		{Action: "Shortsword", Kind: "melee weapon", Average: 5.5},
+		{Action: "Longbow", Kind: "ranged weapon", Average: 6.5},
+	}
+	if dpr := damagePerRound(attacks, nil); dpr != 6.5 {
+		t.Errorf("single attack = %v, want 6.5", dpr)
+	}
+	routine := []routineStep{
+		{Option: 0, Count: 3, Attack: "melee"},
+		{Option: 1, Action: "Longbow", Count: 2},
+	}
+	if dpr := damagePerRound(attacks, routine); dpr != 16.5 {
+		t.Errorf("routine = %v, want 16.5", dpr)
+	}
+}
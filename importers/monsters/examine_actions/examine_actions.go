@@ -1,76 +1,37 @@
 package main
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
+	"open5e_importer/importers/monsters"
 )
 
-type MonsterImport struct {
-	ID                    int
-	Actions               string  `db:"actions"`
-	Alignment             string  `db:"alignment"`
-	ArmorClass            int32   `db:"armor_class"`
-	ArmorDescription      string  `db:"armor_description"`
-	BonusActions          []uint8 `db:"bonus_actions"`
-	ChallengeRating       float32 `db:"challenge_rating"`
-	Charisma              int32   `db:"charisma"`
-	CharismaSave          int32   `db:"charisma_save"`
-	ConditionImmunities   string  `db:"condition_immunities"`
-	Constitution          int32   `db:"constitution"`
-	ConstitutionSave      int32   `db:"constitution_save"`
-	DamageImmunities      string  `db:"damage_immunities"`
-	DamageResistances     string  `db:"damage_resistances"`
-	DamageVulnerabilities string  `db:"damage_vulnerabilities"`
-	Description           string  `db:"description"`
-	Dexterity             int32   `db:"dexterity"`
-	DexteritySave         int32   `db:"dexterity_save"`
-	DocumentLicenseUrl    string  `db:"document_license_url"`
-	DocumentSlug          string  `db:"document_slug"`
-	DocumentTitle         string  `db:"document_title"`
-	DocumentUrl           string  `db:"document_url"`
-	Environments          []uint8 `db:"environments"`
-	Group                 string  `db:"group_name"`
-	HP                    int32   `db:"hp"`
-	HitDice               string  `db:"hit_dice"`
-	Image                 string  `db:"image"`
-	Intelligence          int32   `db:"intelligence"`
-	IntelligenceSave      int32   `db:"intelligence_save"`
-	Languages             string  `db:"languages"`
-	LegendaryActions      []uint8 `db:"legendary_actions"`
-	LegendaryDescription  string  `db:"legendary_description"`
-	Name                  string  `db:"name"`
-	Perception            int32   `db:"perception"`
-	Reactions             []uint8 `db:"reactions"`
-	Senses                string  `db:"senses"`
-	Size                  string  `db:"size"`
-	Skills                []uint8 `db:"skills"`
-	Slug                  string  `db:"slug"`
-	SpecialAbilities      []uint8 `db:"special_abilities"`
-	Speed                 []uint8 `db:"speed"`
-	SpellList             []uint8 `db:"spell_list"`
-	Strength              int32   `db:"strength"`
-	StrengthSave          int32   `db:"strength_save"`
-	Subtype               string  `db:"subtype"`
-	Type                  string  `db:"type"`
-	Wisdom                int32   `db:"wisdom"`
-	WisdomSave            int32   `db:"wisdom_save"`
+type MonsterAction struct {
+	MonsterSlug string `db:"monster_slug"`
+	Kind        string `db:"kind"`
+	Name        string `db:"name"`
+	Description string `db:"description"`
 }
 
 func main() {
 	// Connect to the database:
-	db, err := sqlx.Open("sqlite3", "../../sql_database/monster_imports.db")
+	db, err := sqlx.Open("sqlite3", "../../sql_database/open5e_imports.db")
 	if err != nil {
 		log.Fatalln(err)
 	}
 
-	// Query all monsters:
-	monsters := []MonsterImport{}
-	err = db.Select(&monsters, "SELECT * FROM mob_imports")
+	// Query every monster's actions, in the order of their stat block:
+	actions := []MonsterAction{}
+	err = db.Select(&actions, `
+		SELECT monster_slug, kind, name, description
+		FROM monster_actions
+		ORDER BY monster_slug, id
+	`)
 	if err != nil {
 		log.Fatalln(err)
 	}
@@ -82,32 +43,69 @@ func main() {
 	}
 	defer file.Close()
 
+	// Open a file for the attack coverage report:
+	unparsed, err := os.Create("unparsed_attacks.txt")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer unparsed.Close()
+	attacks, parsed := 0, 0
+
 	// Write all actions to the file:
-	for _, monster := range monsters {
-		var result interface{}
-		if monster.Actions != "null" {
-			err := json.Unmarshal([]byte(monster.Actions), &result)
-			if err != nil {
-				log.Fatalf("%v", err)
-			}
-			data, ok := result.([]interface{})
-			if !ok {
-				log.Fatalf("json is not an array")
-			}
-			for i, item := range data {
-				obj, ok := item.(map[string]interface{})
-				if !ok {
-					log.Fatalf("item %d is not an object", i)
-				}
-				desc, ok := obj["desc"].(string)
-				if !ok {
-					log.Fatalf("desc is not a string")
-				}
-				_, err = file.WriteString(fmt.Sprintf("%s\n", desc))
-				if err != nil {
-					log.Fatalln(err)
-				}
-			}
+	for _, action := range actions {
+		if action.Kind != "action" {
+			continue
+		}
+		_, err = file.WriteString(fmt.Sprintf("%s\n", action.Description))
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		// check whether the attack parser understands it
+		_, err = monsters.ParseAttack(action.Description)
+		if errors.Is(err, monsters.ErrNotAttack) {
+			continue
+		}
+		attacks++
+		if err == nil {
+			parsed++
+			continue
+		}
+		_, err = unparsed.WriteString(fmt.Sprintf("%s: %s: %v\n%s\n\n", action.MonsterSlug, action.Name, err, action.Description))
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	fmt.Printf("parsed %d of %d attacks (%.1f%%), see unparsed_attacks.txt for the rest\n",
+		parsed, attacks, 100*float64(parsed)/float64(attacks))
+
+	// Report multiattacks naming actions the monster doesn't have:
+	unknown, err := os.Create("unknown_multiattacks.txt")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer unknown.Close()
+	flagged := 0
+
+	names := map[string][]string{}
+	for _, action := range actions {
+		names[action.MonsterSlug] = append(names[action.MonsterSlug], action.Name)
+	}
+	for _, action := range actions {
+		if !monsters.IsMultiattack(action.Name) {
+			continue
+		}
+		multi := monsters.ParseMultiattack(action.Description, names[action.MonsterSlug])
+		if len(multi.Unknown) == 0 && len(multi.Options) > 0 {
+			continue
+		}
+		flagged++
+		_, err = unknown.WriteString(fmt.Sprintf("%s: unknown %q in %q\n%s\n\n", action.MonsterSlug,
+			multi.Unknown, names[action.MonsterSlug], action.Description))
+		if err != nil {
+			log.Fatalln(err)
 		}
 	}
+	fmt.Printf("%d multiattacks could not be resolved, see unknown_multiattacks.txt\n", flagged)
 }
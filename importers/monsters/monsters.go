@@ -0,0 +1,383 @@
+package monsters
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"open5e_importer/open5e"
+	"open5e_importer/statblock"
+)
+
+// Import fetches every monster from the Open5e API and writes them to db,
+// returning the number of monsters imported.
+func Import(db *sqlx.DB, client *open5e.Client, version open5e.Version) (int, error) {
+	return open5e.Import(client, monsterResource, version, func(page []MonsterImport) error {
+		return writeMonstersToDB(db, page)
+	})
+}
+
+type MonsterImport struct {
+	Actions               []interface{}            `json:"actions"`
+	Alignment             string                   `json:"alignment"`
+	ArmorClass            int32                    `json:"armor_class"`
+	ArmorDescription      string                   `json:"armor_desc"`
+	BonusActions          []interface{}            `json:"bonus_actions"`
+	ChallengeRating       float32                  `json:"cr"`
+	ChallengeRatingText   string                   `json:"challenge_rating"`
+	Charisma              int32                    `json:"charisma"`
+	CharismaSave          *int32                   `json:"charisma_save"`
+	ConditionImmunities   string                   `json:"condition_immunities"`
+	Constitution          int32                    `json:"constitution"`
+	ConstitutionSave      *int32                   `json:"constitution_save"`
+	DamageImmunities      string                   `json:"damage_immunities"`
+	DamageResistances     string                   `json:"damage_resistances"`
+	DamageVulnerabilities string                   `json:"damage_vulnerabilities"`
+	Description           string                   `json:"desc"`
+	Dexterity             int32                    `json:"dexterity"`
+	DexteritySave         *int32                   `json:"dexterity_save"`
+	DocumentSlug          string                   `json:"document__slug"`
+	Environments          []interface{}            `json:"environments"`
+	Group                 string                   `json:"group"`
+	HP                    int32                    `json:"hit_points"`
+	HitDice               string                   `json:"hit_dice"`
+	Image                 string                   `json:"img_main"`
+	Intelligence          int32                    `json:"intelligence"`
+	IntelligenceSave      *int32                   `json:"intelligence_save"`
+	Languages             string                   `json:"languages"`
+	LegendaryActions      []interface{}            `json:"legendary_actions"`
+	LegendaryDescription  string                   `json:"legendary_desc"`
+	Name                  string                   `json:"name"`
+	Perception            int32                    `json:"perception"`
+	Reactions             []interface{}            `json:"reactions"`
+	Senses                string                   `json:"senses"`
+	Size                  string                   `json:"size"`
+	Skills                map[string]interface{}   `json:"skills"`
+	Slug                  string                   `json:"slug"`
+	SpecialAbilities      []map[string]interface{} `json:"special_abilities"`
+	Speed                 interface{}              `json:"speed"`
+	SpellList             []string                 `json:"spell_list"`
+	Strength              int32                    `json:"strength"`
+	StrengthSave          *int32                   `json:"strength_save"`
+	Subtype               string                   `json:"subtype"`
+	Type                  string                   `json:"type"`
+	Wisdom                int32                    `json:"wisdom"`
+	WisdomSave            *int32                   `json:"wisdom_save"`
+}
+
+// json keys of v1 results whose MonsterImport field isn't named after them
+var monsterAliases = map[string]string{
+	"cr":               "ChallengeRating",
+	"challenge_rating": "ChallengeRatingText",
+	"legendary_desc":   "LegendaryDescription",
+	"hit_points":       "HP",
+	"img_main":         "Image",
+	"armor_desc":       "ArmorDescription",
+	"desc":             "Description",
+}
+
+var monsterResource = open5e.Resource[MonsterImport]{
+	V1Path:   "monsters",
+	DecodeV1: open5e.DecodeV1[MonsterImport](monsterAliases),
+	V2Path:   "creatures",
+	DecodeV2: decodeMonsterV2,
+}
+
+func writeMonstersToDB(db *sqlx.DB, monsters []MonsterImport) error {
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS mob_imports (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			alignment TEXT,
+			armor_class INTEGER,
+			armor_description TEXT,
+			challenge_rating FLOAT,
+			charisma INTEGER,
+			condition_immunities TEXT,
+			constitution INTEGER,
+			damage_immunities TEXT,
+			damage_resistances TEXT,
+			damage_vulnerabilities TEXT,
+			description TEXT,
+			dexterity INTEGER,
+			document_slug TEXT REFERENCES documents(slug),
+			group_name TEXT,
+			hp INTEGER,
+			hit_dice TEXT,
+			image TEXT,
+			intelligence INTEGER,
+			languages TEXT,
+			legendary_description TEXT,
+			name TEXT,
+			perception INTEGER,
+			senses TEXT,
+			size TEXT,
+			slug TEXT,
+			speed TEXT,
+			spell_list TEXT,
+			strength INTEGER,
+			subtype TEXT,
+			type TEXT,
+			wisdom INTEGER,
+			walk_speed INTEGER,
+			swim_speed INTEGER,
+			fly_speed INTEGER,
+			climb_speed INTEGER,
+			burrow_speed INTEGER,
+			hover BOOLEAN,
+			passive_perception INTEGER,
+			telepathy INTEGER,
+			challenge_rating_text TEXT,
+			xp INTEGER,
+			proficiency_bonus INTEGER,
+			cr_band TEXT,
+			cr_mismatch BOOLEAN
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create mob_imports: %w", err)
+	}
+	for _, create := range []func(*sqlx.DB) error{
+		createMonsterAttackTables,
+		createMonsterSaveTables,
+		createMonsterMultiattackTable,
+		createMonsterSpellcastingTables,
+		createMonsterSensesTables,
+		createMonsterDefenseTables,
+		createMonsterChildTables,
+		createMonsterDerivedTable,
+	} {
+		err = create(db)
+		if err != nil {
+			return err
+		}
+	}
+
+	spells, err := loadSpellIndex(db)
+	if err != nil {
+		return err
+	}
+
+	for _, monster := range monsters {
+		// a monster and its child rows are replaced together, so a
+		// re-import never leaves rows of the previous import behind
+		tx, err := db.Beginx()
+		if err != nil {
+			return err
+		}
+		err = writeMonsterToDB(tx, monster, spells)
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("%s: %w", monster.Slug, err)
+		}
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writes the monster to mob_imports and its other tables, after deleting
+// what an earlier import wrote for it.
+func writeMonsterToDB(tx *sqlx.Tx, monster MonsterImport, spells spellIndex) error {
+	err := deleteMonsterFromDB(tx, monster.Slug)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO mob_imports
+		(
+			alignment,
+			armor_class,
+			armor_description,
+			challenge_rating,
+			charisma,
+			condition_immunities,
+			constitution,
+			damage_immunities,
+			damage_resistances,
+			damage_vulnerabilities,
+			description,
+			dexterity,
+			document_slug,
+			group_name,
+			hp,
+			hit_dice,
+			image,
+			intelligence,
+			languages,
+			legendary_description,
+			name,
+			perception,
+			senses,
+			size,
+			slug,
+			speed,spell_list,
+			strength,
+			subtype,
+			type,
+			wisdom,
+			walk_speed,
+			swim_speed,
+			fly_speed,
+			climb_speed,
+			burrow_speed,
+			hover,
+			passive_perception,
+			telepathy,
+			challenge_rating_text,
+			xp,
+			proficiency_bonus,
+			cr_band,
+			cr_mismatch)
+		VALUES
+		(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+	`
+	speedJson, err := json.Marshal(monster.Speed)
+	if err != nil {
+		return err
+	}
+
+	spellListJson, err := json.Marshal(monster.SpellList)
+	if err != nil {
+		return err
+	}
+
+	speed, err := statblock.ParseSpeed(monster.Speed)
+	if err != nil {
+		fmt.Printf("Speed of %s not understood: %v\n", monster.Slug, err)
+	}
+	// the columns derived from the challenge rating are left NULL
+	// when it isn't understood
+	var crText, xp, proficiencyBonus, crBand interface{}
+	cr, crMismatch, err := monsterChallengeRating(monster)
+	if err != nil {
+		fmt.Printf("Challenge rating of %s not understood: %v\n", monster.Slug, err)
+	} else {
+		crText, xp, proficiencyBonus, crBand = cr.String(), cr.XP(), cr.ProficiencyBonus(), cr.Band()
+	}
+	if crMismatch {
+		fmt.Printf("Challenge rating of %s is %s but cr is %v\n", monster.Slug, cr, monster.ChallengeRating)
+	}
+	senses := statblock.ParseSenses(monster.Senses)
+	languages := statblock.ParseLanguages(monster.Languages)
+
+	_, err = tx.Exec(query,
+		monster.Alignment,
+		monster.ArmorClass,
+		monster.ArmorDescription,
+		monster.ChallengeRating,
+		monster.Charisma,
+		monster.ConditionImmunities,
+		monster.Constitution,
+		monster.DamageImmunities,
+		monster.DamageResistances,
+		monster.DamageVulnerabilities,
+		monster.Description,
+		monster.Dexterity,
+		monster.DocumentSlug,
+		monster.Group,
+		monster.HP,
+		monster.HitDice,
+		monster.Image,
+		monster.Intelligence,
+		monster.Languages,
+		monster.LegendaryDescription,
+		monster.Name,
+		monster.Perception,
+		monster.Senses,
+		monster.Size,
+		monster.Slug,
+		speedJson,
+		spellListJson,
+		monster.Strength,
+		monster.Subtype,
+		monster.Type,
+		monster.Wisdom,
+		speed.Walk,
+		speed.Swim,
+		speed.Fly,
+		speed.Climb,
+		speed.Burrow,
+		speed.Hover,
+		senses.PassivePerception,
+		languages.Telepathy,
+		crText,
+		xp,
+		proficiencyBonus,
+		crBand,
+		crMismatch)
+	if err != nil {
+		return err
+	}
+
+	err = writeMonsterChildrenToDB(tx, monster)
+	if err != nil {
+		return err
+	}
+	err = writeMonsterSensesToDB(tx, monster.Slug, senses, languages)
+	if err != nil {
+		return err
+	}
+
+	for _, write := range []func(sqlx.Execer, MonsterImport) error{
+		writeMonsterAttacksToDB,
+		writeMonsterSavesToDB,
+		writeMonsterMultiattackToDB,
+		writeMonsterDefensesToDB,
+	} {
+		err = write(tx, monster)
+		if err != nil {
+			return err
+		}
+	}
+	return writeMonsterSpellcastingToDB(tx, monster, spells)
+}
+
+// deletes the monster and every row written for it from the other monster
+// tables, so it can be written again.  rows of the damage tables are found
+// through the attack or save effect they belong to.  its monster_derived
+// row goes too, rather than being left stale until Derive runs again.
+func deleteMonsterFromDB(db sqlx.Execer, monsterSlug string) error {
+	for _, query := range []string{
+		`DELETE FROM monster_attack_damage WHERE attack_id IN (SELECT id FROM monster_attacks WHERE monster_slug = ?)`,
+		`DELETE FROM monster_save_damage WHERE save_effect_id IN (SELECT id FROM monster_save_effects WHERE monster_slug = ?)`,
+		`DELETE FROM monster_spells WHERE monster_slug = ?`,
+		`DELETE FROM monster_spellcasting WHERE monster_slug = ?`,
+		`DELETE FROM monster_spell_mismatches WHERE monster_slug = ?`,
+		`DELETE FROM monster_attacks WHERE monster_slug = ?`,
+		`DELETE FROM monster_save_effects WHERE monster_slug = ?`,
+		`DELETE FROM monster_multiattack WHERE monster_slug = ?`,
+		`DELETE FROM monster_senses WHERE monster_slug = ?`,
+		`DELETE FROM monster_languages WHERE monster_slug = ?`,
+		`DELETE FROM monster_damage_modifiers WHERE monster_slug = ?`,
+		`DELETE FROM monster_condition_immunities WHERE monster_slug = ?`,
+		`DELETE FROM monster_actions WHERE monster_slug = ?`,
+		`DELETE FROM monster_skills WHERE monster_slug = ?`,
+		`DELETE FROM monster_environments WHERE monster_slug = ?`,
+		`DELETE FROM monster_saves WHERE monster_slug = ?`,
+		`DELETE FROM monster_derived WHERE monster_slug = ?`,
+		`DELETE FROM mob_imports WHERE slug = ?`,
+	} {
+		_, err := db.Exec(query, monsterSlug)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func convertJsonToMonsterImports(jsonData []byte, version open5e.Version) ([]MonsterImport, string) {
+	monsters, nextUrl, err := open5e.DecodePage(monsterResource, version, jsonData)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if nextUrl == "" {
+		return monsters, "DONE"
+	}
+	return monsters, nextUrl
+}
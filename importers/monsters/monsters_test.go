@@ -0,0 +1,113 @@
+package monsters
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/open5e"
+)
+
+func TestImportMonsters(t *testing.T) {
+	err := os.Remove("../../sql_database/monsters_test.db")
+	if err != nil {
+		if os.IsNotExist(err) {
+			// don't worry about it!
+		} else {
+			log.Fatal(err)
+		}
+	}
+	db, err := sqlx.Open("sqlite3", "../../sql_database/monsters_test.db")
+	if err != nil {
+		log.Fatalf("Failed to open sqlite db: %v", err)
+	}
+	if err != nil {
+		if os.IsNotExist(err) {
+			// don't worry about it!
+		} else {
+			t.Fatal(err)
+		}
+	}
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	monsters, _ := convertJsonToMonsterImports(data, open5e.V1)
+	if err := writeMonstersToDB(db, monsters); err != nil {
+		t.Fatal(err)
+	}
+
+	// movement a monster doesn't have is NULL
+	var speeds []string
+	err = db.Select(&speeds, `
+		SELECT slug || ' ' || COALESCE(walk_speed, '-') || ' ' || COALESCE(swim_speed, '-') || ' ' || COALESCE(fly_speed, '-')
+		FROM mob_imports WHERE slug IN ('aboleth', 'adult-blue-dragon') ORDER BY slug
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"aboleth 10 40 -", "adult-blue-dragon 40 - 80"}; !reflect.DeepEqual(speeds, want) {
+		t.Errorf("speeds = %q, want %q", speeds, want)
+	}
+}
+
+// the v2 fixture holds the first few creatures of the v1 fixture, so both
+// should decode into the same MonsterImport apart from the slugs.
+func TestDecodeMonstersV2(t *testing.T) {
+	v1Data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2Data, err := ioutil.ReadFile("./test_data/testdata_v2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1Monsters, _ := convertJsonToMonsterImports(v1Data, open5e.V1)
+	v2Monsters, next := convertJsonToMonsterImports(v2Data, open5e.V2)
+	if next != "https://api.open5e.com/v2/creatures/?limit=3&page=2" {
+		t.Errorf("unexpected next url %q", next)
+	}
+	if len(v2Monsters) != 3 {
+		t.Fatalf("expected 3 creatures, got %d", len(v2Monsters))
+	}
+
+	for i, v2 := range v2Monsters {
+		v1 := v1Monsters[i]
+		if v2.Slug != "srd_"+v1.Slug || v2.DocumentSlug != "srd" {
+			t.Errorf("unexpected keys %q, %q for %s", v2.Slug, v2.DocumentSlug, v1.Slug)
+		}
+		v2.Slug, v2.DocumentSlug = v1.Slug, v1.DocumentSlug
+		for _, field := range []string{
+			"Name", "Size", "Type", "HP", "HitDice", "ChallengeRating", "ArmorClass",
+			"Strength", "ConstitutionSave", "Perception", "Senses", "Languages",
+			"Speed", "Actions", "LegendaryActions", "Environments",
+		} {
+			v1Json, _ := json.Marshal(reflect.ValueOf(v1).FieldByName(field).Interface())
+			v2Json, _ := json.Marshal(reflect.ValueOf(v2).FieldByName(field).Interface())
+			if !reflect.DeepEqual(names(v1Json, field), names(v2Json, field)) {
+				t.Errorf("%s: %s differs between v1 and v2:\n%s\n%s", v1.Slug, field, v1Json, v2Json)
+			}
+		}
+	}
+}
+
+// actions are compared by name and description only, since v1 carries
+// damage fields which the v2 decoder does not reproduce.
+func names(data []byte, field string) interface{} {
+	if !strings.HasSuffix(field, "Actions") {
+		return string(data)
+	}
+	var actions []map[string]interface{}
+	json.Unmarshal(data, &actions)
+	var res []string
+	for _, action := range actions {
+		res = append(res, fmt.Sprintf("%v: %v", action["name"], action["desc"]))
+	}
+	return res
+}
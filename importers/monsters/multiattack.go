@@ -0,0 +1,363 @@
+package monsters
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Multiattack is a monster's Multiattack action resolved into routines
+// the monster can choose between, e.g. "The trollkin makes two spear
+// attacks or one bite attack and two claw attacks" has two options.  each
+// option's steps are taken in order.
+type Multiattack struct {
+	Options [][]MultiattackStep
+	Unknown []string // mentioned actions the monster doesn't have
+}
+
+// MultiattackStep is one action taken Count times.  Action is the name of
+// one of the monster's own actions, as written in its stat block, or the
+// words of the description when Known is false.  a step naming no action,
+// like "two melee attacks", leaves Action empty and says which attacks it
+// allows in Attack.
+type MultiattackStep struct {
+	Action string
+	Count  int32
+	Attack string // "melee", "ranged", "weapon" or "any"
+	Known  bool
+}
+
+var counts = map[string]int32{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10,
+}
+
+var (
+	multiTrigger  = regexp.MustCompile(`(?i)\b(a|an|one|two|three|four|five|six|seven|eight|nine|ten)\b|\b(?:can\s+)?uses?\s+(?:its\s+)?`)
+	multiWithIts  = regexp.MustCompile(`(?i)^\s+(?:attacks?\s*,?\s+)?(?:either\s+)?with\s+(?:its|his|her|their)\s+`)
+	multiGeneric  = regexp.MustCompile(`(?i)^(?:(melee-or-ranged|melee|ranged)(?: weapon)?|(weapon)) attacks?\b`)
+	multiHeader   = regexp.MustCompile(`(?i)^\s+attacks?\b`)
+	multiNamed    = regexp.MustCompile(`(?i)^\s*((?:[\w'’-]+\s+){0,3}?[\w'’-]+)\s+attacks?\b`)
+	multiWithName = regexp.MustCompile(`^((?:[\w'’-]+\s+){0,3}?[\w'’-]+?)(?:\s+attacks?)?(?:[,.;:]|\s+and\b|\s+or\b|$)`)
+	multiUseName  = regexp.MustCompile(`^([A-Z][\w'’-]*(?:\s+(?:of\s+|the\s+)?[A-Z][\w'’-]*)*)`)
+	multiTimes    = regexp.MustCompile(`(?i)^\s+(once|twice|thrice|(two|three|four) times)\b`)
+	multiOr       = regexp.MustCompile(`,?\s+or\s+`)
+	multiEither   = regexp.MustCompile(`(?i)\bmelee or ranged\b`)
+	multiOption   = regexp.MustCompile(`(?i)^(?:it\s+)?(?:then\s+)?(?:makes?\s+|can\s+)?(?:a|an|one|two|three|four|five|six|seven|eight|nine|ten|uses?)\b`)
+	multiAlt      = regexp.MustCompile(`(?i)^(?:with\s+)?(?:its\s+|a\s+|an\s+)?`)
+	multiSkip     = regexp.MustCompile(`(?i)^(?:if|when|while|until|after|each|the target)\b|\bin place of\b|\breplace`)
+	multiGroup    = regexp.MustCompile(`(?i)^(?:in [\w ]+ form\b|alternatively\b)`)
+)
+
+// multiattackNames matches the monster's own action names against the
+// words of its multiattack.
+type multiattackNames struct {
+	keys  []string          // lower case, longest first
+	names map[string]string // key to the action's name
+}
+
+func newMultiattackNames(actionNames []string) multiattackNames {
+	n := multiattackNames{names: map[string]string{}}
+	add := func(key, name string) {
+		if _, ok := n.names[key]; !ok && key != "" {
+			n.names[key] = name
+			n.keys = append(n.keys, key)
+		}
+	}
+	for _, name := range actionNames {
+		if IsMultiattack(name) {
+			continue
+		}
+		display := strings.ToLower(ParseUsage(name).Name)
+		bare := strings.TrimSpace(usageParens.ReplaceAllString(display, ""))
+		for _, key := range []string{display, bare} {
+			add(key, name)
+			add(key+"s", name)
+			add(key+"es", name)
+			add(strings.TrimSuffix(key, "s"), name)
+		}
+	}
+	sort.SliceStable(n.keys, func(i, j int) bool { return len(n.keys[i]) > len(n.keys[j]) })
+	return n
+}
+
+// match returns the action named at the start of s and the length of the
+// name, including a trailing "attack" or "attacks".
+func (n multiattackNames) match(s string) (string, int, bool) {
+	lower := strings.ToLower(s)
+	for _, key := range n.keys {
+		if !strings.HasPrefix(lower, key) {
+			continue
+		}
+		end := len(key)
+		if end < len(s) && (unicode.IsLetter(rune(s[end])) || s[end] == '-') {
+			continue
+		}
+		if m := multiHeader.FindStringIndex(s[end:]); m != nil {
+			end += m[1]
+		}
+		return n.names[key], end, true
+	}
+	return "", 0, false
+}
+
+// IsMultiattack reports whether an action is a Multiattack, whatever its
+// name says in parentheses, e.g. "Multiattack (Humanoid or Hybrid Form
+// Only)".
+func IsMultiattack(name string) bool {
+	bare := usageParens.ReplaceAllString(ParseUsage(name).Name, "")
+	return strings.EqualFold(strings.TrimSpace(bare), "multiattack")
+}
+
+// ParseMultiattack resolves a Multiattack description against the names
+// of the monster's actions.  sentences about what happens when the
+// attacks hit, or which trade an attack for something else, are ignored.
+func ParseMultiattack(desc string, actionNames []string) Multiattack {
+	names := newMultiattackNames(actionNames)
+	var multi Multiattack
+	newGroup := true
+	for _, sentence := range splitSentences(desc) {
+		for _, clause := range strings.Split(sentence, "; ") {
+			clause = strings.TrimSpace(clause)
+			if multiSkip.MatchString(clause) {
+				continue
+			}
+			if multiGroup.MatchString(clause) {
+				newGroup = true
+			}
+
+			for i, seg := range splitMultiattackOptions(clause, names) {
+				switch {
+				case seg.alt != nil:
+					if len(multi.Options) == 0 || len(multi.Options[len(multi.Options)-1]) == 0 {
+						continue
+					}
+					last := multi.Options[len(multi.Options)-1]
+					option := append([]MultiattackStep{}, last...)
+					step := *seg.alt
+					step.Count = option[len(option)-1].Count
+					option[len(option)-1] = step
+					multi.Options = append(multi.Options, option)
+				case i == 0 && !newGroup && len(multi.Options) > 0:
+					last := len(multi.Options) - 1
+					multi.Options[last] = append(multi.Options[last], seg.steps...)
+				default:
+					multi.Options = append(multi.Options, seg.steps)
+				}
+				newGroup = false
+			}
+		}
+	}
+
+	options := multi.Options[:0]
+	for _, option := range multi.Options {
+		if len(option) == 0 {
+			continue
+		}
+		options = append(options, option)
+		for _, step := range option {
+			if !step.Known && !contains(multi.Unknown, step.Action) {
+				multi.Unknown = append(multi.Unknown, step.Action)
+			}
+		}
+	}
+	multi.Options = options
+	return multi
+}
+
+// multiattackSegment is part of a clause split at "or": either the steps
+// of a new option, or a single step to swap for the last step of the
+// previous option, as in "one with its bite and one with its claws or
+// mace".
+type multiattackSegment struct {
+	steps []MultiattackStep
+	alt   *MultiattackStep
+}
+
+func splitMultiattackOptions(clause string, names multiattackNames) []multiattackSegment {
+	var segs []multiattackSegment
+	var text []string
+	// "melee or ranged attacks" is a single step, not two options
+	clause = multiEither.ReplaceAllString(clause, "melee-or-ranged")
+	for _, part := range multiOr.Split(clause, -1) {
+		if len(text) > 0 {
+			if steps := multiattackSteps(part, names); len(steps) > 0 && multiOption.MatchString(part) {
+				segs = append(segs, multiattackSegment{steps: multiattackSteps(strings.Join(text, " or "), names)})
+				text = nil
+			} else if alt, ok := multiattackAlt(part, names); ok {
+				segs = append(segs, multiattackSegment{steps: multiattackSteps(strings.Join(text, " or "), names)})
+				segs = append(segs, multiattackSegment{alt: &alt})
+				text = nil
+				continue
+			}
+		}
+		text = append(text, part)
+	}
+	if len(text) > 0 {
+		segs = append(segs, multiattackSegment{steps: multiattackSteps(strings.Join(text, " or "), names)})
+	}
+	return segs
+}
+
+func multiattackAlt(part string, names multiattackNames) (MultiattackStep, bool) {
+	part = strings.TrimRight(part[len(multiAlt.FindString(part)):], ".")
+	if name, n, ok := names.match(part); ok && strings.TrimSpace(part[n:]) == "" {
+		return MultiattackStep{Action: name, Known: true}, true
+	}
+	if m := multiGeneric.FindStringSubmatch(part + " attacks"); m != nil && len(m[0]) >= len(part) {
+		return MultiattackStep{Attack: genericAttack(m), Known: true}, true
+	}
+	return MultiattackStep{}, false
+}
+
+func genericAttack(m []string) string {
+	switch {
+	case m[2] != "" || strings.EqualFold(m[1], "melee-or-ranged"):
+		return "weapon"
+	default:
+		return strings.ToLower(m[1])
+	}
+}
+
+// multiattackSteps reads the steps of a single option in order.
+func multiattackSteps(text string, names multiattackNames) []MultiattackStep {
+	var steps []MultiattackStep
+	header := int32(0)
+	end := 0
+	for _, m := range multiTrigger.FindAllStringSubmatchIndex(text, -1) {
+		if m[0] < end {
+			continue
+		}
+
+		if m[2] < 0 {
+			// "uses Frightful Presence", "can use its Spit Fire twice"
+			pos := m[1]
+			step := MultiattackStep{Count: 1}
+			if name, n, ok := names.match(text[pos:]); ok {
+				step.Action, step.Known = name, true
+				pos += n
+			} else if phrase := multiUseName.FindString(text[pos:]); phrase != "" {
+				step.Action = phrase
+				pos += len(phrase)
+			} else {
+				continue
+			}
+			if t := multiTimes.FindStringSubmatch(text[pos:]); t != nil {
+				step.Count = map[string]int32{"once": 1, "twice": 2, "thrice": 3}[strings.ToLower(t[1])]
+				if t[2] != "" {
+					step.Count = counts[strings.ToLower(t[2])]
+				}
+				pos += len(t[0])
+			}
+			steps = append(steps, step)
+			end = pos
+			continue
+		}
+
+		// "two claw attacks", "one with its bite", "One Bite"
+		word := strings.ToLower(text[m[2]:m[3]])
+		count := counts[word]
+		pos := m[1]
+		with := false
+		if w := multiWithIts.FindStringIndex(text[pos:]); w != nil {
+			pos += w[1]
+			with = true
+		} else {
+			pos += len(text[pos:]) - len(strings.TrimLeft(text[pos:], " "))
+		}
+
+		if name, n, ok := names.match(text[pos:]); ok {
+			steps = append(steps, MultiattackStep{Action: name, Count: count, Known: true})
+			end = pos + n
+		} else if g := multiGeneric.FindStringSubmatch(text[pos:]); g != nil {
+			steps = append(steps, MultiattackStep{Attack: genericAttack(g), Count: count, Known: true})
+			end = pos + len(g[0])
+		} else if with {
+			if w := multiWithName.FindStringSubmatch(text[pos:]); w != nil {
+				steps = append(steps, MultiattackStep{Action: w[1], Count: count})
+				end = pos + len(w[1])
+			}
+		} else if multiHeader.MatchString(text[m[1]:]) {
+			header = count
+		} else if u := multiNamed.FindStringSubmatch(text[pos:]); u != nil && word != "a" && word != "an" {
+			// an unknown name is only trusted after a number, as "a" and
+			// "an" begin too many other phrases
+			steps = append(steps, MultiattackStep{Action: u[1], Count: count})
+			end = pos + len(u[0])
+		}
+	}
+
+	if len(steps) == 0 && header > 0 {
+		steps = append(steps, MultiattackStep{Attack: "any", Count: header, Known: true})
+	}
+	return steps
+}
+
+func createMonsterMultiattackTable(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS monster_multiattack (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			monster_slug TEXT,
+			action_position INTEGER,
+			option INTEGER,
+			position INTEGER,
+			action_name TEXT,
+			count INTEGER,
+			attack TEXT,
+			known BOOLEAN
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create monster_multiattack: %w", err)
+	}
+	return nil
+}
+
+// writes the monster's multiattack routine to monster_multiattack, one
+// row per step.  steps naming an action the monster doesn't have are
+// written with known = 0, so
+//
+//	SELECT DISTINCT monster_slug FROM monster_multiattack WHERE NOT known
+//
+// lists the monsters whose multiattack can't be executed as written.  a
+// monster with a Multiattack for each of its forms has the options of all
+// of them numbered together, and action_position is the position in
+// monster_actions of the Multiattack each option comes from.
+func writeMonsterMultiattackToDB(db sqlx.Execer, monster MonsterImport) error {
+	actions := monsterActions(monster)
+	var names []string
+	for _, action := range actions {
+		names = append(names, action.Name)
+	}
+
+	option, actionPosition := 0, -1
+	for _, action := range actions {
+		if action.Kind != "action" {
+			continue
+		}
+		actionPosition++
+		if !IsMultiattack(action.Name) {
+			continue
+		}
+		multi := ParseMultiattack(action.Desc, names)
+		for _, steps := range multi.Options {
+			for position, step := range steps {
+				_, err := db.Exec(`
+					INSERT INTO monster_multiattack
+					(monster_slug, action_position, option, position, action_name, count, attack, known)
+					VALUES
+					(?, ?, ?, ?, ?, ?, ?, ?)
+				`, monster.Slug, actionPosition, option, position, step.Action, step.Count, step.Attack, step.Known)
+				if err != nil {
+					return err
+				}
+			}
+			option++
+		}
+	}
+	return nil
+}
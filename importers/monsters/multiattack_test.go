@@ -0,0 +1,127 @@
+package monsters
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestParseMultiattack(t *testing.T) {
+	step := func(action string, count int32) MultiattackStep {
+		return MultiattackStep{Action: action, Count: count, Known: true}
+	}
+	cases := []struct {
+		desc    string
+		actions []string
+		multi   Multiattack
+	}{
+		{
+			"The dragon can use its Frightful Presence. It then makes three attacks: one with its bite and two with its claws.",
+			[]string{"Multiattack", "Bite", "Claw", "Tail", "Frightful Presence", "Fire Breath (Recharge 5–6)"},
+			Multiattack{Options: [][]MultiattackStep{{step("Frightful Presence", 1), step("Bite", 1), step("Claw", 2)}}},
+		},
+		{
+			"The trollkin makes two spear attacks or one bite attack and two claw attacks.",
+			[]string{"Multiattack", "Spear", "Bite", "Claw"},
+			Multiattack{Options: [][]MultiattackStep{{step("Spear", 2)}, {step("Bite", 1), step("Claw", 2)}}},
+		},
+		{
+			"The drider makes three attacks, either with its longsword or its longbow. It can replace one of those attacks with a bite attack.",
+			[]string{"Multiattack", "Bite", "Longsword", "Longbow"},
+			Multiattack{Options: [][]MultiattackStep{{step("Longsword", 3)}, {step("Longbow", 3)}}},
+		},
+		{
+			"In humanoid form, the werebat makes two mace attacks. In hybrid form, it makes two attacks: one with its bite and one with its claws or mace.",
+			[]string{"Multiattack (Humanoid or Hybrid Form Only)", "Bite (Bat or Hybrid Form Only)", "Claws (Hybrid Form Only)", "Mace (Humanoid or Hybrid Form Only)"},
+			Multiattack{Options: [][]MultiattackStep{
+				{step("Mace (Humanoid or Hybrid Form Only)", 2)},
+				{step("Bite (Bat or Hybrid Form Only)", 1), step("Claws (Hybrid Form Only)", 1)},
+				{step("Bite (Bat or Hybrid Form Only)", 1), step("Mace (Humanoid or Hybrid Form Only)", 1)},
+			}},
+		},
+		{
+			"One Bite and two Claws or it makes three Psychic Bolts. It can replace one attack with Spellcasting.",
+			[]string{"Multiattack", "Bite", "Claw", "Psychic Bolt"},
+			Multiattack{Options: [][]MultiattackStep{{step("Bite", 1), step("Claw", 2)}, {step("Psychic Bolt", 3)}}},
+		},
+		{
+			"The kelp eel makes two attacks with its kelp tendrils, uses Reel, and makes two attacks with its slam.",
+			[]string{"Multiattack", "Kelp Tendrils", "Reel", "Slam"},
+			Multiattack{Options: [][]MultiattackStep{{step("Kelp Tendrils", 2), step("Reel", 1), step("Slam", 2)}}},
+		},
+		{
+			"The ssadar makes two attacks: one with its bite and one with its longsword. Alternatively, it can use Spit Fire twice.",
+			[]string{"Multiattack", "Bite", "Longsword", "Spit Fire (Recharge 5–6)"},
+			Multiattack{Options: [][]MultiattackStep{{step("Bite", 1), step("Longsword", 1)}, {step("Spit Fire (Recharge 5–6)", 2)}}},
+		},
+		{
+			"The bandit lord makes three melee or ranged attacks.",
+			[]string{"Multiattack", "Shortsword", "Light Crossbow"},
+			Multiattack{Options: [][]MultiattackStep{{{Attack: "weapon", Count: 3, Known: true}}}},
+		},
+		{
+			"The wereboar makes two attacks, only one of which can be with its tusks.",
+			[]string{"Multiattack (Humanoid or Hybrid Form Only)", "Maul", "Tusks"},
+			Multiattack{Options: [][]MultiattackStep{{{Attack: "any", Count: 2, Known: true}}}},
+		},
+		{
+			"The apaxrusl makes two slam attacks. If both attacks hit the same creature, the target is blinded for 1 minute.",
+			[]string{"Multiattack", "Fist"},
+			Multiattack{
+				Options: [][]MultiattackStep{{{Action: "slam", Count: 2}}},
+				Unknown: []string{"slam"},
+			},
+		},
+	}
+	for _, c := range cases {
+		if got := ParseMultiattack(c.desc, c.actions); !reflect.DeepEqual(got, c.multi) {
+			t.Errorf("ParseMultiattack(%q)\n got %+v\nwant %+v", c.desc, got, c.multi)
+		}
+	}
+}
+
+func TestWriteMonsterMultiattack(t *testing.T) {
+	err := os.Remove("../../sql_database/multiattack_test.db")
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	db, err := sqlx.Open("sqlite3", "../../sql_database/multiattack_test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// a Multiattack for each form, both restricted to it
+	action := func(name, desc string) interface{} {
+		return map[string]interface{}{"name": name, "desc": desc}
+	}
+	werebat := MonsterImport{Slug: "werebat", Actions: []interface{}{
+		action("Multiattack (Humanoid Form Only)", "The werebat makes two mace attacks."),
+		action("Multiattack (Hybrid Form Only)", "The werebat makes two attacks: one with its bite and one with its claws."),
+		action("Bite (Bat or Hybrid Form Only)", ""),
+		action("Claws (Hybrid Form Only)", ""),
+		action("Mace (Humanoid or Hybrid Form Only)", ""),
+	}}
+	if err := writeMonstersToDB(db, []MonsterImport{werebat}); err != nil {
+		t.Fatal(err)
+	}
+
+	var steps []string
+	err = db.Select(&steps, `
+		SELECT action_position || ' ' || option || ' ' || position || ' ' || count || ' ' || action_name
+		FROM monster_multiattack WHERE monster_slug = 'werebat' ORDER BY id
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"0 0 0 2 Mace (Humanoid or Hybrid Form Only)",
+		"1 1 0 1 Bite (Bat or Hybrid Form Only)",
+		"1 1 1 1 Claws (Hybrid Form Only)",
+	}
+	if !reflect.DeepEqual(steps, want) {
+		t.Errorf("werebat multiattack = %q, want %q", steps, want)
+	}
+}
@@ -0,0 +1,304 @@
+package monsters
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SaveEffect is a saving throw called for by a monster's action or
+// ability, e.g. "must make a DC 22 Dexterity saving throw, taking 54
+// (12d8) acid damage on a failed save, or half as much damage on a
+// successful one".  what a successful save does of its own, as in "On a
+// success, the target takes 5 (2d4) psychic damage", is kept apart from
+// what a failed one does.
+type SaveEffect struct {
+	DC                int32
+	Ability           string   // "strength", "dexterity", ..., empty when not given
+	Damage            []Damage // taken on a failed save
+	OnSuccess         string   // "half" or "none"
+	Conditions        []string // applied on a failed save
+	Duration          string   // e.g. "1 minute" or "until the end of its next turn"
+	SuccessDamage     []Damage // taken on a successful save
+	SuccessConditions []string // applied on a successful save
+	SuccessDuration   string
+	Area              Area
+}
+
+// Area is the shape an effect fills, e.g. a "60-foot line that is 5 feet
+// wide" or a "20-foot-radius sphere".  sizes are in feet.
+type Area struct {
+	Shape string
+	Size  int32
+	Width int32
+}
+
+var abilities = map[string]string{
+	"strength": "strength", "str": "strength",
+	"dexterity": "dexterity", "dex": "dexterity",
+	"constitution": "constitution", "con": "constitution",
+	"intelligence": "intelligence", "int": "intelligence",
+	"wisdom": "wisdom", "wis": "wisdom",
+	"charisma": "charisma", "cha": "charisma",
+}
+
+var conditionNames = []string{
+	"blinded", "charmed", "deafened", "frightened", "grappled", "incapacitated",
+	"invisible", "paralyzed", "petrified", "poisoned", "prone", "restrained",
+	"stunned", "unconscious", "exhaustion",
+}
+
+var (
+	saveDC       = regexp.MustCompile(`DC (\d+) ([A-Z][a-z]+)( saving throw| save| check)?|DC (\d+)( saving throw)|([A-Z][a-z]+)( saving throw) \(DC (\d+)\)`)
+	saveDamage   = regexp.MustCompile(`(\d+) \((\d+d\d+)\s*(?:([+\-–])\s*(\d+))?\) ([a-z]+)`)
+	saveHalf     = regexp.MustCompile(`(?i)\bhalf (?:as much )?(?:\w+ )?damage\b|\bhalf\b[^.]*\bsuccess|\bDC \d+ \w+ half\b`)
+	saveDuration = regexp.MustCompile(`\bfor (\d+ (?:round|minute|hour|day|week)s?)\b|\b(until the (?:end|start) of (?:its|the target's|the creature's|your|the [\w-]+'s) next turn)\b`)
+	saveSuccess  = regexp.MustCompile(`^On a (?:success|successful save)\b`)
+	saveFollowUp = regexp.MustCompile(`^(?:On a (?:failed save|failure|success|successful save)|If (?:the|its|it|that) (?:saving throw |save |target |creature )?fails|(?:It|The target|The creature) takes \d)`)
+	areaShape    = regexp.MustCompile(`(\d+)[- ](?:foot|feet|ft\.?)(?:[- ]radius)?[- ](line|cone|cube|sphere|cylinder|radius|emanation|square)(?: that is (\d+) (?:feet|foot|ft\.?) wide)?`)
+	conditionRe  = regexp.MustCompile(`\b(` + strings.Join(conditionNames, "|") + `)\b`)
+)
+
+// ParseSaveEffects returns every saving throw called for in a
+// description, in the order they appear.  ability checks such as "a DC 15
+// Strength check" are not saving throws and are left out.
+func ParseSaveEffects(desc string) []SaveEffect {
+	var effects []SaveEffect
+	area := parseArea(desc)
+	sentences := splitSentences(desc)
+
+	for i, sentence := range sentences {
+		for _, loc := range saveDC.FindAllStringSubmatchIndex(sentence, -1) {
+			group := func(i int) string {
+				if loc[2*i] < 0 {
+					return ""
+				}
+				return sentence[loc[2*i]:loc[2*i+1]]
+			}
+			// "DC 14 Wisdom saving throw", "DC 16 saving throw" (the
+			// ability is left out) or "Dexterity saving throw (DC 13)"
+			dc, abilityName, kind := group(1), group(2), group(3)
+			if group(4) != "" {
+				dc, abilityName, kind = group(4), "", group(5)
+			} else if group(8) != "" {
+				dc, abilityName, kind = group(8), group(6), group(7)
+			}
+			ability, ok := abilities[strings.ToLower(abilityName)]
+			if (!ok && abilityName != "") || kind == " check" {
+				continue
+			}
+			parenthesised := loc[0] > 0 && strings.LastIndex(sentence[:loc[0]], "(") > strings.LastIndex(sentence[:loc[0]], ")")
+			if kind == "" && !parenthesised {
+				continue
+			}
+
+			// the effect is described after the DC, and in any sentences
+			// that follow on from it, except for the terse "18 (4d8)
+			// necrotic (DC 13 Con half)" style where it comes first.
+			// what happens on a success is kept apart, so its damage,
+			// conditions and duration aren't taken for the failure's
+			effect := sentence[loc[0]:]
+			if parenthesised {
+				effect = sentence
+			}
+			success := ""
+			for _, next := range sentences[i+1:] {
+				if !saveFollowUp.MatchString(next) {
+					break
+				}
+				if saveSuccess.MatchString(next) {
+					success += " " + next
+				} else {
+					effect += " " + next
+				}
+			}
+
+			save := SaveEffect{
+				DC:        atoi32(dc),
+				Ability:   ability,
+				OnSuccess: "none",
+				Area:      area,
+			}
+			save.Damage = damageIn(effect)
+			save.SuccessDamage = damageIn(success)
+			// "On a success, it takes half as much damage" halves the
+			// failure's damage
+			if len(save.Damage) > 0 && saveHalf.MatchString(effect+success) {
+				save.OnSuccess = "half"
+			}
+			save.Conditions = conditionsIn(effect)
+			save.SuccessConditions = conditionsIn(success)
+			save.Duration = durationIn(effect)
+			save.SuccessDuration = durationIn(success)
+			effects = append(effects, save)
+		}
+	}
+	return effects
+}
+
+// damageIn lists the damage dealt in text.
+func damageIn(text string) []Damage {
+	var list []Damage
+	for _, m := range saveDamage.FindAllStringSubmatch(text, -1) {
+		// the type is sometimes chosen elsewhere, e.g. "taking 27 (6d8)
+		// damage of the most recent type dealt to it"
+		if m[5] == "damage" {
+			m[5] = ""
+		}
+		if damageTypes[m[5]] || m[5] == "" {
+			list = append(list, damage(m))
+		}
+	}
+	return list
+}
+
+// durationIn is the first duration given in text, or empty.
+func durationIn(text string) string {
+	if m := saveDuration.FindStringSubmatch(text); m != nil {
+		return m[1] + m[2]
+	}
+	return ""
+}
+
+// conditionsIn lists the conditions named in text, each once.
+func conditionsIn(text string) []string {
+	var conditions []string
+	for _, m := range conditionRe.FindAllStringSubmatch(text, -1) {
+		if !contains(conditions, m[1]) {
+			conditions = append(conditions, m[1])
+		}
+	}
+	return conditions
+}
+
+func parseArea(desc string) Area {
+	m := areaShape.FindStringSubmatch(desc)
+	if m == nil {
+		return Area{}
+	}
+	return Area{Shape: m[2], Size: atoi32(m[1]), Width: atoi32(m[3])}
+}
+
+// splitSentences splits on a full stop followed by a capital letter, so
+// abbreviations such as "30 ft. of it" stay in their sentence.
+func splitSentences(desc string) []string {
+	var sentences []string
+	start := 0
+	runes := []rune(desc)
+	for i := 0; i < len(runes)-2; i++ {
+		if runes[i] == '.' && unicode.IsSpace(runes[i+1]) {
+			j := i + 1
+			for j < len(runes) && unicode.IsSpace(runes[j]) {
+				j++
+			}
+			if j < len(runes) && unicode.IsUpper(runes[j]) {
+				sentences = append(sentences, strings.TrimSpace(string(runes[start:i+1])))
+				start = j
+				i = j - 1
+			}
+		}
+	}
+	return append(sentences, strings.TrimSpace(string(runes[start:])))
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func createMonsterSaveTables(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS monster_save_effects (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			monster_slug TEXT,
+			action_kind TEXT,
+			action_name TEXT,
+			dc INTEGER,
+			ability TEXT,
+			on_success TEXT,
+			conditions TEXT,
+			duration TEXT,
+			success_conditions TEXT,
+			success_duration TEXT,
+			area_shape TEXT,
+			area_size INTEGER,
+			area_width INTEGER
+		);
+		CREATE TABLE IF NOT EXISTS monster_save_damage (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			save_effect_id INTEGER REFERENCES monster_save_effects(id),
+			on_success BOOLEAN,
+			position INTEGER,
+			average INTEGER,
+			dice TEXT,
+			bonus INTEGER,
+			damage_type TEXT
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create monster_save_effects: %w", err)
+	}
+	return nil
+}
+
+// writes a row to monster_save_effects for every saving throw in the
+// monster's actions and special abilities, with the damage taken on a
+// failed save in monster_save_damage.  damage a successful save deals of
+// its own is there too, with on_success set.
+func writeMonsterSavesToDB(db sqlx.Execer, monster MonsterImport) error {
+	for _, action := range monsterActions(monster) {
+		for _, save := range ParseSaveEffects(action.Desc) {
+			conditionsJson, err := json.Marshal(save.Conditions)
+			if err != nil {
+				return err
+			}
+			successConditionsJson, err := json.Marshal(save.SuccessConditions)
+			if err != nil {
+				return err
+			}
+
+			res, err := db.Exec(`
+				INSERT INTO monster_save_effects
+				(monster_slug, action_kind, action_name, dc, ability, on_success, conditions, duration,
+				success_conditions, success_duration, area_shape, area_size, area_width)
+				VALUES
+				(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`, monster.Slug, action.Kind, action.Name, save.DC, save.Ability, save.OnSuccess,
+				conditionsJson, save.Duration, successConditionsJson, save.SuccessDuration,
+				save.Area.Shape, save.Area.Size, save.Area.Width)
+			if err != nil {
+				return err
+			}
+			effectId, err := res.LastInsertId()
+			if err != nil {
+				return err
+			}
+
+			for _, list := range []struct {
+				onSuccess bool
+				damage    []Damage
+			}{{false, save.Damage}, {true, save.SuccessDamage}} {
+				for i, damage := range list.damage {
+					_, err = db.Exec(`
+						INSERT INTO monster_save_damage
+						(save_effect_id, on_success, position, average, dice, bonus, damage_type)
+						VALUES
+						(?, ?, ?, ?, ?, ?, ?)
+					`, effectId, list.onSuccess, i, damage.Average, damage.Dice, damage.Bonus, damage.Type)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package monsters
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSaveEffects(t *testing.T) {
+	cases := []struct {
+		desc    string
+		effects []SaveEffect
+	}{
+		{
+			"The dragon exhales acid in a 60-foot line that is 5 feet wide. Each creature in that line must make a DC 18 Dexterity saving throw, taking 54 (12d8) acid damage on a failed save, or half as much damage on a successful one.",
+			[]SaveEffect{{DC: 18, Ability: "dexterity", OnSuccess: "half",
+				Damage: []Damage{{Average: 54, Dice: "12d8", Type: "acid"}},
+				Area:   Area{Shape: "line", Size: 60, Width: 5}}},
+		},
+		{
+			"The aboleth targets one creature it can see within 30 ft. of it. The target must succeed on a DC 14 Wisdom saving throw or be magically charmed by the aboleth until the aboleth dies or until it is on a different plane of existence from the target.",
+			[]SaveEffect{{DC: 14, Ability: "wisdom", OnSuccess: "none", Conditions: []string{"charmed"}}},
+		},
+		{
+			"Each creature of the dragon's choice that is within 120 feet of the dragon and aware of it must succeed on a DC 21 Wisdom saving throw or become frightened for 1 minute. A creature can repeat the saving throw at the end of each of its turns.",
+			[]SaveEffect{{DC: 21, Ability: "wisdom", OnSuccess: "none", Conditions: []string{"frightened"}, Duration: "1 minute"}},
+		},
+		{
+			"The dragon exhales fire in a 60-foot cone. Each creature in that area must make a DC 21 Dexterity saving throw, taking 63 (18d6) fire damage on a failed save, or half as much damage on a successful one.",
+			[]SaveEffect{{DC: 21, Ability: "dexterity", OnSuccess: "half",
+				Damage: []Damage{{Average: 63, Dice: "18d6", Type: "fire"}},
+				Area:   Area{Shape: "cone", Size: 60}}},
+		},
+		{
+			// the hit damage comes before the save, so isn't part of it
+			"Melee Weapon Attack: +9 to hit, reach 10 ft., one target. Hit: 14 (2d8 + 5) piercing damage, and the target must make a DC 15 Constitution saving throw. On a failure, the target takes 14 (4d6) poison damage and is incapacitated for 1 minute. On a success, the target takes half as much poison damage.",
+			[]SaveEffect{{DC: 15, Ability: "constitution", OnSuccess: "half",
+				Damage:     []Damage{{Average: 14, Dice: "4d6", Type: "poison"}},
+				Conditions: []string{"incapacitated"}, Duration: "1 minute"}},
+		},
+		{
+			"Each creature within 20' of the mush marcher: 18 (4d8) poison and is poisoned (DC 13 Con half damage and isn’t poisoned).",
+			[]SaveEffect{{DC: 13, Ability: "constitution", OnSuccess: "half",
+				Damage:     []Damage{{Average: 18, Dice: "4d8", Type: "poison"}},
+				Conditions: []string{"poisoned"}}},
+		},
+		{
+			// the success's conditions aren't the failure's
+			"The target must make a DC 13 Constitution saving throw. On a failed save, the target is paralyzed for 1 minute. On a successful save, the target is poisoned until the end of its next turn.",
+			[]SaveEffect{{DC: 13, Ability: "constitution", OnSuccess: "none",
+				Conditions: []string{"paralyzed"}, Duration: "1 minute",
+				SuccessConditions: []string{"poisoned"}, SuccessDuration: "until the end of its next turn"}},
+		},
+		{
+			// damage dealt only on a success isn't the failure's
+			"The target must make a DC 12 Wisdom saving throw. On a failed save, the target is frightened for 1 minute. On a successful save, the target takes 5 (2d4) psychic damage.",
+			[]SaveEffect{{DC: 12, Ability: "wisdom", OnSuccess: "none",
+				Conditions: []string{"frightened"}, Duration: "1 minute",
+				SuccessDamage: []Damage{{Average: 5, Dice: "2d4", Type: "psychic"}}}},
+		},
+		{
+			"As an action, the restrained creature can make a DC 15 Strength check, bursting through the icy shadow on a success.",
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		effects := ParseSaveEffects(c.desc)
+		if !reflect.DeepEqual(effects, c.effects) {
+			t.Errorf("parsed %q as\n%+v\nexpected\n%+v", c.desc, effects, c.effects)
+		}
+	}
+}
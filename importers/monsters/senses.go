@@ -0,0 +1,60 @@
+package monsters
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/statblock"
+)
+
+func createMonsterSensesTables(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS monster_senses (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			monster_slug TEXT,
+			sense TEXT,
+			range INTEGER,
+			note TEXT
+		);
+		CREATE TABLE IF NOT EXISTS monster_languages (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			monster_slug TEXT,
+			language TEXT,
+			speaks BOOLEAN
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create monster_senses: %w", err)
+	}
+	return nil
+}
+
+// writes a row to monster_senses for each of the monster's special senses
+// and to monster_languages for each language it speaks or understands.
+// passive Perception and telepathy are single values kept on mob_imports.
+func writeMonsterSensesToDB(db sqlx.Execer, monsterSlug string, senses statblock.Senses, languages statblock.Languages) error {
+	for _, sense := range senses.Senses {
+		_, err := db.Exec(`
+			INSERT INTO monster_senses
+			(monster_slug, sense, range, note)
+			VALUES
+			(?, ?, ?, ?)
+		`, monsterSlug, sense.Type, sense.Range, sense.Note)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, language := range languages.Languages {
+		_, err := db.Exec(`
+			INSERT INTO monster_languages
+			(monster_slug, language, speaks)
+			VALUES
+			(?, ?, ?)
+		`, monsterSlug, language.Name, language.Speaks)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
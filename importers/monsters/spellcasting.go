@@ -0,0 +1,339 @@
+package monsters
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Spellcasting is a monster's "Spellcasting" or "Innate Spellcasting"
+// special ability, e.g. "The lich is an 18th-level spellcaster. Its
+// spellcasting ability is Intelligence (spell save DC 20, +12 to hit with
+// spell attacks). The lich has the following wizard spells prepared: ...".
+type Spellcasting struct {
+	Name        string
+	Innate      bool
+	CasterLevel int32
+	Ability     string // "intelligence", "wisdom", ...
+	SaveDC      int32
+	AttackBonus int32
+	Class       string // the class whose spell list is used, if given
+	Groups      []SpellGroup
+}
+
+// SpellGroup is one line of a spellcasting ability, e.g. "1st level (4
+// slots): detect magic, magic missile" or "3/day each: fly, tongues".
+type SpellGroup struct {
+	Frequency string // "at will", "slots" or "day"
+	Level     int32  // spell level of prepared spells, 0 for cantrips
+	Slots     int32
+	SlotLevel int32 // the level slots are cast at, which warlocks don't vary
+	PerDay    int32
+	Spells    []Spell
+}
+
+// Spell is a spell named by a spellcasting ability.  Note is anything the
+// ability says about it in parentheses, e.g. "self only".
+type Spell struct {
+	Name string
+	Slug string
+	Note string
+}
+
+var (
+	castLevel   = regexp.MustCompile(`(\d+)(?:st|nd|rd|th)-level spellcaster`)
+	castAbility = regexp.MustCompile(`(?i)spellcasting ability is (\w+)`)
+	castDC      = regexp.MustCompile(`(?i)spell save DC (\d+)`)
+	castAttack  = regexp.MustCompile(`(?i)([+-]\d+) to hit with spell attacks`)
+	castClass   = regexp.MustCompile(`(?i)following (\w+) spells`)
+	castGroup   = regexp.MustCompile(`(?i)(cantrips \(at will\)|(\d+)(?:st|nd|rd|th)(?:\s*[-–]\s*(\d+)(?:st|nd|rd|th))? level \(([^)]*)\)|at will|(\d+)/day(?: each)?)\s*:`)
+	castSlots   = regexp.MustCompile(`(\d+)(?: (\d+)(?:st|nd|rd|th)-level)? slots?`)
+	spellSlug   = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// ParseSpellcasting reads a spellcasting special ability.  the spell
+// lists run from each group's heading to the next heading or the end of
+// the line.
+func ParseSpellcasting(name, desc string) Spellcasting {
+	sc := Spellcasting{
+		Name:   name,
+		Innate: strings.Contains(strings.ToLower(name), "innate") || strings.Contains(desc, "innately cast"),
+	}
+	if m := castLevel.FindStringSubmatch(desc); m != nil {
+		sc.CasterLevel = atoi32(m[1])
+	}
+	if m := castAbility.FindStringSubmatch(desc); m != nil {
+		sc.Ability = abilities[strings.ToLower(m[1])]
+	}
+	if m := castDC.FindStringSubmatch(desc); m != nil {
+		sc.SaveDC = atoi32(m[1])
+	}
+	if m := castAttack.FindStringSubmatch(desc); m != nil {
+		sc.AttackBonus = atoi32(strings.TrimPrefix(m[1], "+"))
+	}
+	if m := castClass.FindStringSubmatch(desc); m != nil {
+		sc.Class = strings.ToLower(m[1])
+	}
+
+	headings := castGroup.FindAllStringSubmatchIndex(desc, -1)
+	for i, h := range headings {
+		end := len(desc)
+		if i+1 < len(headings) {
+			end = headings[i+1][0]
+		}
+		text := desc[h[1]:end]
+		if nl := strings.Index(text, "\n"); nl >= 0 {
+			text = text[:nl]
+		}
+
+		var group SpellGroup
+		heading := strings.ToLower(desc[h[2]:h[3]])
+		switch {
+		case strings.HasPrefix(heading, "cantrips"), heading == "at will":
+			group.Frequency = "at will"
+		case h[10] >= 0:
+			group.Frequency = "day"
+			group.PerDay = atoi32(desc[h[10]:h[11]])
+		default:
+			group.Frequency = "slots"
+			group.Level = atoi32(desc[h[4]:h[5]])
+			if h[6] >= 0 {
+				// "1st-5th level (3 5th-level slots)"
+				group.Level = atoi32(desc[h[6]:h[7]])
+			}
+			if m := castSlots.FindStringSubmatch(desc[h[8]:h[9]]); m != nil {
+				group.Slots = atoi32(m[1])
+				group.SlotLevel = group.Level
+				if m[2] != "" {
+					group.SlotLevel = atoi32(m[2])
+				}
+			}
+		}
+		group.Spells = parseSpellNames(text)
+		sc.Groups = append(sc.Groups, group)
+	}
+	return sc
+}
+
+// parseSpellNames splits a list of spells at the commas outside of
+// parentheses, e.g. "create food and water (can create wine instead of
+// water), tongues".
+func parseSpellNames(text string) []Spell {
+	var spells []Spell
+	depth, start := 0, 0
+	add := func(item string) {
+		var spell Spell
+		if open := strings.Index(item, "("); open >= 0 {
+			spell.Note = strings.Trim(item[open:], "() ")
+			item = item[:open]
+		}
+		spell.Name = strings.ToLower(strings.Trim(item, " *†•.\t"))
+		if spell.Name == "" {
+			return
+		}
+		spell.Slug = slugify(spell.Name)
+		spells = append(spells, spell)
+	}
+	for i, r := range text {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				add(text[start:i])
+				start = i + 1
+			}
+		}
+	}
+	add(text[start:])
+	return spells
+}
+
+func slugify(name string) string {
+	name = strings.NewReplacer("'", "", "’", "").Replace(strings.ToLower(name))
+	return strings.Trim(spellSlug.ReplaceAllString(name, "-"), "-")
+}
+
+// spellListSlugs returns the slugs of the spell urls in a monster's
+// spell_list.
+func spellListSlugs(spellList []string) []string {
+	var slugs []string
+	for _, url := range spellList {
+		slugs = append(slugs, path.Base(strings.TrimSuffix(url, "/")))
+	}
+	return slugs
+}
+
+// spellIndex finds imported spells by name.  a spell's slug is only known
+// once it's imported, since v2 keys carry their document, e.g.
+// "srd_fireball", so a name is looked up rather than slugified.
+type spellIndex map[string][]importedSpell
+
+type importedSpell struct {
+	Name         string `db:"name"`
+	Slug         string `db:"slug"`
+	DocumentSlug string `db:"document_slug"`
+}
+
+// loadSpellIndex reads spell_imports, which is empty when the spells
+// haven't been imported.
+func loadSpellIndex(db sqlx.Queryer) (spellIndex, error) {
+	index := spellIndex{}
+	var tables int
+	err := sqlx.Get(db, &tables, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'spell_imports'`)
+	if err != nil || tables == 0 {
+		return index, err
+	}
+	var spells []importedSpell
+	err = sqlx.Select(db, &spells, `SELECT name, slug, COALESCE(document_slug, '') AS document_slug FROM spell_imports ORDER BY slug`)
+	if err != nil {
+		return nil, err
+	}
+	for _, spell := range spells {
+		name := strings.ToLower(spell.Name)
+		index[name] = append(index[name], spell)
+	}
+	return index, nil
+}
+
+// slug returns the slug of the spell called name, preferring the one from
+// the given document when several documents have it.  a spell which isn't
+// imported keeps the slug made from its name.
+func (index spellIndex) slug(name, document string) string {
+	spells := index[strings.ToLower(name)]
+	for _, spell := range spells {
+		if spell.DocumentSlug == document {
+			return spell.Slug
+		}
+	}
+	if len(spells) > 0 {
+		return spells[0].Slug
+	}
+	return slugify(name)
+}
+
+func createMonsterSpellcastingTables(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS monster_spellcasting (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			monster_slug TEXT,
+			ability_name TEXT,
+			innate BOOLEAN,
+			caster_level INTEGER,
+			ability TEXT,
+			save_dc INTEGER,
+			attack_bonus INTEGER,
+			spell_class TEXT
+		);
+		CREATE TABLE IF NOT EXISTS monster_spells (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			spellcasting_id INTEGER REFERENCES monster_spellcasting(id),
+			monster_slug TEXT,
+			spell_slug TEXT,
+			frequency TEXT,
+			level INTEGER,
+			slots INTEGER,
+			slot_level INTEGER,
+			uses_per_day INTEGER,
+			note TEXT
+		);
+		CREATE TABLE IF NOT EXISTS monster_spell_mismatches (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			monster_slug TEXT,
+			spell_slug TEXT,
+			problem TEXT
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create monster_spellcasting: %w", err)
+	}
+	return nil
+}
+
+// writes the monster's spellcasting abilities to monster_spellcasting
+// with a row per spell in monster_spells.  the spells are then compared
+// with the monster's spell_list and any spell found in only one of them is
+// written to monster_spell_mismatches.  levels are only written for
+// prepared spells, innate spellcasting doesn't give them.  spells are
+// written with their slug in spell_imports when they've been imported.
+func writeMonsterSpellcastingToDB(db sqlx.Execer, monster MonsterImport, spells spellIndex) error {
+	var described []string
+	for _, action := range monsterActions(monster) {
+		if action.Kind != "special_ability" || !strings.Contains(strings.ToLower(action.Name), "spellcasting") {
+			continue
+		}
+		sc := ParseSpellcasting(action.Name, action.Desc)
+
+		res, err := db.Exec(`
+			INSERT INTO monster_spellcasting
+			(monster_slug, ability_name, innate, caster_level, ability, save_dc, attack_bonus, spell_class)
+			VALUES
+			(?, ?, ?, ?, ?, ?, ?, ?)
+		`, monster.Slug, sc.Name, sc.Innate, sc.CasterLevel, sc.Ability, sc.SaveDC, sc.AttackBonus, sc.Class)
+		if err != nil {
+			return err
+		}
+		spellcastingId, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		for _, group := range sc.Groups {
+			var level interface{}
+			if !sc.Innate {
+				level = group.Level
+			}
+			for _, spell := range group.Spells {
+				spell.Slug = spells.slug(spell.Name, monster.DocumentSlug)
+				_, err = db.Exec(`
+					INSERT INTO monster_spells
+					(spellcasting_id, monster_slug, spell_slug, frequency, level, slots, slot_level, uses_per_day, note)
+					VALUES
+					(?, ?, ?, ?, ?, ?, ?, ?, ?)
+				`, spellcastingId, monster.Slug, spell.Slug, group.Frequency, level, group.Slots,
+					group.SlotLevel, group.PerDay, spell.Note)
+				if err != nil {
+					return err
+				}
+				if !contains(described, spell.Slug) {
+					described = append(described, spell.Slug)
+				}
+			}
+		}
+	}
+
+	// v2 creatures have no spell_list to check against
+	if len(monster.SpellList) == 0 {
+		return nil
+	}
+	listed := spellListSlugs(monster.SpellList)
+	for _, mismatch := range []struct {
+		slugs   []string
+		against []string
+		problem string
+	}{
+		{described, listed, "not in spell_list"},
+		{listed, described, "not in description"},
+	} {
+		for _, slug := range mismatch.slugs {
+			if contains(mismatch.against, slug) {
+				continue
+			}
+			_, err := db.Exec(`
+				INSERT INTO monster_spell_mismatches
+				(monster_slug, spell_slug, problem)
+				VALUES
+				(?, ?, ?)
+			`, monster.Slug, slug, mismatch.problem)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package monsters
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/open5e"
+)
+
+func TestParseSpellcasting(t *testing.T) {
+	spells := func(names ...string) []Spell {
+		var list []Spell
+		for _, name := range names {
+			list = append(list, Spell{Name: name, Slug: slugify(name)})
+		}
+		return list
+	}
+	cases := []struct {
+		name string
+		desc string
+		sc   Spellcasting
+	}{
+		{
+			"Spellcasting",
+			"The acolyte is a 1st-level spellcaster. Its spellcasting ability is Wisdom (spell save DC 12, +4 to hit with spell attacks). The acolyte has following cleric spells prepared:\n\n* Cantrips (at will): light, sacred flame, thaumaturgy\n* 1st level (3 slots): bless, cure wounds, sanctuary",
+			Spellcasting{Name: "Spellcasting", CasterLevel: 1, Ability: "wisdom", SaveDC: 12, AttackBonus: 4, Class: "cleric",
+				Groups: []SpellGroup{
+					{Frequency: "at will", Spells: spells("light", "sacred flame", "thaumaturgy")},
+					{Frequency: "slots", Level: 1, Slots: 3, SlotLevel: 1, Spells: spells("bless", "cure wounds", "sanctuary")},
+				}},
+		},
+		{
+			"Innate Spellcasting",
+			"The djinni's innate spellcasting ability is Charisma (spell save DC 17, +9 to hit with spell attacks). It can innately cast the following spells, requiring no material components:\n\nAt will: detect evil and good, detect magic, thunderwave\n3/day each: create food and water (can create wine instead of water), tongues, wind walk\n1/day each: conjure elemental (air elemental only), plane shift",
+			Spellcasting{Name: "Innate Spellcasting", Innate: true, Ability: "charisma", SaveDC: 17, AttackBonus: 9,
+				Groups: []SpellGroup{
+					{Frequency: "at will", Spells: spells("detect evil and good", "detect magic", "thunderwave")},
+					{Frequency: "day", PerDay: 3, Spells: []Spell{
+						{Name: "create food and water", Slug: "create-food-and-water", Note: "can create wine instead of water"},
+						{Name: "tongues", Slug: "tongues"}, {Name: "wind walk", Slug: "wind-walk"}}},
+					{Frequency: "day", PerDay: 1, Spells: []Spell{
+						{Name: "conjure elemental", Slug: "conjure-elemental", Note: "air elemental only"},
+						{Name: "plane shift", Slug: "plane-shift"}}},
+				}},
+		},
+		{
+			"Spellcasting",
+			"The fiend is a 9th-level spellcaster. Its spellcasting ability is Charisma (spell save DC 15). It knows the following warlock spells:\n• 1st–5th level (2 5th-level slots): hellish rebuke*, Tasha's hideous laughter",
+			Spellcasting{Name: "Spellcasting", CasterLevel: 9, Ability: "charisma", SaveDC: 15, Class: "warlock",
+				Groups: []SpellGroup{
+					{Frequency: "slots", Level: 5, Slots: 2, SlotLevel: 5, Spells: []Spell{
+						{Name: "hellish rebuke", Slug: "hellish-rebuke"},
+						{Name: "tasha's hideous laughter", Slug: "tashas-hideous-laughter"}}},
+				}},
+		},
+	}
+	for _, c := range cases {
+		if got := ParseSpellcasting(c.name, c.desc); !reflect.DeepEqual(got, c.sc) {
+			t.Errorf("ParseSpellcasting(%q)\n got %+v\nwant %+v", c.desc, got, c.sc)
+		}
+	}
+}
+
+func TestMonsterSpellSlugs(t *testing.T) {
+	err := os.Remove("../../sql_database/spellcasting_test.db")
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	db, err := sqlx.Open("sqlite3", "../../sql_database/spellcasting_test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// spell_imports is owned by the spells importer, so only the columns
+	// the monsters read are created here
+	_, err = db.Exec(`
+		CREATE TABLE spell_imports (name TEXT, slug TEXT, document_slug TEXT);
+		INSERT INTO spell_imports (name, slug, document_slug) VALUES
+			('Sacred Flame', 'a5e_sacred-flame', 'a5e'),
+			('Sacred Flame', 'srd_sacred-flame', 'srd'),
+			('Bless', 'a5e_bless', 'a5e');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile("./test_data/testdata_v2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	monsters, _ := convertJsonToMonsterImports(data, open5e.V2)
+	if err := writeMonstersToDB(db, monsters); err != nil {
+		t.Fatal(err)
+	}
+
+	var slugs []string
+	err = db.Select(&slugs, `SELECT spell_slug FROM monster_spells WHERE monster_slug = 'srd_acolyte' ORDER BY id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the acolyte's own document is preferred, then any document, and a
+	// spell which isn't imported is slugified
+	want := []string{"light", "srd_sacred-flame", "thaumaturgy", "a5e_bless", "cure-wounds", "sanctuary"}
+	if !reflect.DeepEqual(slugs, want) {
+		t.Errorf("acolyte spells %q, want %q", slugs, want)
+	}
+}
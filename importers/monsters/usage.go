@@ -0,0 +1,91 @@
+package monsters
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Usage is the limit an action's name puts on it, e.g. "Enslave (3/Day)",
+// "Fire Breath (Recharge 5–6)", "Rage (Recharges after a Short or Long
+// Rest)" or "Wing Attack (Costs 2 Actions)".  Name is the display name with
+// those mechanics removed; anything else in parentheses, like "(Bear Form
+// Only)", is kept.  zero values mean no such limit.
+type Usage struct {
+	Name          string
+	PerDay        int32
+	RechargeMin   int32
+	RechargeMax   int32
+	Rest          string // "short", "short or long" or "long"
+	LegendaryCost int32
+}
+
+var (
+	usageParens   = regexp.MustCompile(`\s*\(([^()]*)\)`)
+	usagePerDay   = regexp.MustCompile(`(?i)^(\d+)\s*/\s*day(?: each)?$`)
+	usageRecharge = regexp.MustCompile(`(?i)^recharge (\d)(?:\s*[-–—]\s*(\d))?$`)
+	usageRest     = regexp.MustCompile(`(?i)^recharges? after a (short or long|short|long) rest$`)
+	usageCost     = regexp.MustCompile(`(?i)^costs (\d+) actions?$`)
+)
+
+// ParseUsage splits the usage limits out of an action's name.  each
+// parenthetical is read as a list of clauses separated by commas or
+// semicolons, and only the clauses which aren't mechanics stay in Name.
+func ParseUsage(name string) Usage {
+	var usage Usage
+	clean := usageParens.ReplaceAllStringFunc(name, func(paren string) string {
+		var kept []string
+		inner := usageParens.FindStringSubmatch(paren)[1]
+		for _, clause := range strings.FieldsFunc(inner, func(r rune) bool { return r == ',' || r == ';' }) {
+			clause = strings.TrimSpace(clause)
+			if !usage.read(clause) {
+				kept = append(kept, clause)
+			}
+		}
+		if len(kept) == 0 {
+			return ""
+		}
+		return " (" + strings.Join(kept, ", ") + ")"
+	})
+	usage.Name = strings.TrimSpace(clean)
+	return usage
+}
+
+// read records clause in usage if it is a usage limit.
+func (u *Usage) read(clause string) bool {
+	if m := usagePerDay.FindStringSubmatch(clause); m != nil {
+		u.PerDay = atoi32(m[1])
+		return true
+	}
+	if m := usageRecharge.FindStringSubmatch(clause); m != nil {
+		u.RechargeMin = atoi32(m[1])
+		u.RechargeMax = u.RechargeMin
+		if m[2] != "" {
+			u.RechargeMax = atoi32(m[2])
+		}
+		return true
+	}
+	if m := usageRest.FindStringSubmatch(clause); m != nil {
+		u.Rest = strings.ToLower(m[1])
+		return true
+	}
+	if m := usageCost.FindStringSubmatch(clause); m != nil {
+		u.LegendaryCost = atoi32(m[1])
+		return true
+	}
+	return false
+}
+
+// Kind is how the action's uses are limited, "per_day", "recharge" or
+// "rest", or empty when they aren't.  a legendary action's cost is apart
+// from these.
+func (u Usage) Kind() string {
+	switch {
+	case u.PerDay > 0:
+		return "per_day"
+	case u.RechargeMin > 0:
+		return "recharge"
+	case u.Rest != "":
+		return "rest"
+	}
+	return ""
+}
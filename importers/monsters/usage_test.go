@@ -0,0 +1,48 @@
+package monsters
+
+import "testing"
+
+func TestParseUsage(t *testing.T) {
+	cases := []struct {
+		name  string
+		usage Usage
+	}{
+		{"Bite", Usage{Name: "Bite"}},
+		{"Enslave (3/day)", Usage{Name: "Enslave", PerDay: 3}},
+		{"Legendary Resistance (3/Day)", Usage{Name: "Legendary Resistance", PerDay: 3}},
+		{"Fire Breath (Recharge 5–6)", Usage{Name: "Fire Breath", RechargeMin: 5, RechargeMax: 6}},
+		{"Acid Breath (Recharge 5-6)", Usage{Name: "Acid Breath", RechargeMin: 5, RechargeMax: 6}},
+		{"Lightning Storm (Recharge 6)", Usage{Name: "Lightning Storm", RechargeMin: 6, RechargeMax: 6}},
+		{"Rage (Recharges after a Short or Long Rest)", Usage{Name: "Rage", Rest: "short or long"}},
+		{"Summon (Recharge after a Long Rest)", Usage{Name: "Summon", Rest: "long"}},
+		{"Second Wind (Recharges after a Short Rest)", Usage{Name: "Second Wind", Rest: "short"}},
+		{"Wing Attack (Costs 2 Actions)", Usage{Name: "Wing Attack", LegendaryCost: 2}},
+		{"Bite (Bear Form Only)", Usage{Name: "Bite (Bear Form Only)"}},
+		{"Gore (Recharge 5–6; Bull Form Only)", Usage{Name: "Gore (Bull Form Only)", RechargeMin: 5, RechargeMax: 6}},
+		{"Teleport (1/Day, Costs 3 Actions)", Usage{Name: "Teleport", PerDay: 1, LegendaryCost: 3}},
+	}
+	for _, c := range cases {
+		if got := ParseUsage(c.name); got != c.usage {
+			t.Errorf("ParseUsage(%q) = %+v, want %+v", c.name, got, c.usage)
+		}
+	}
+}
+
+func TestUsageKind(t *testing.T) {
+	cases := []struct {
+		name string
+		kind string
+	}{
+		{"Bite", ""},
+		{"Enslave (3/day)", "per_day"},
+		{"Fire Breath (Recharge 5–6)", "recharge"},
+		{"Rage (Recharges after a Short or Long Rest)", "rest"},
+		{"Wing Attack (Costs 2 Actions)", ""},
+		{"Teleport (1/Day, Costs 3 Actions)", "per_day"},
+	}
+	for _, c := range cases {
+		if got := ParseUsage(c.name).Kind(); got != c.kind {
+			t.Errorf("ParseUsage(%q).Kind() = %q, want %q", c.name, got, c.kind)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package monsters
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"open5e_importer/open5e"
+)
+
+// the v2 API serves monsters as creatures, with nested documents, sizes
+// and types, and with all of a creature's actions in a single list.
+type creatureV2 struct {
+	Key                    string                 `json:"key"`
+	Name                   string                 `json:"name"`
+	Desc                   string                 `json:"desc"`
+	Document               open5e.Named           `json:"document"`
+	Size                   open5e.Named           `json:"size"`
+	Type                   open5e.Named           `json:"type"`
+	Subcategory            string                 `json:"subcategory"`
+	Group                  string                 `json:"group"`
+	Alignment              string                 `json:"alignment"`
+	ArmorClass             int32                  `json:"armor_class"`
+	ArmorDetail            string                 `json:"armor_detail"`
+	HitPoints              int32                  `json:"hit_points"`
+	HitDice                string                 `json:"hit_dice"`
+	ChallengeRatingDecimal json.Number            `json:"challenge_rating_decimal"`
+	ChallengeRatingText    string                 `json:"challenge_rating_text"`
+	Speed                  map[string]interface{} `json:"speed"`
+	AbilityScores          map[string]int32       `json:"ability_scores"`
+	SavingThrows           map[string]int32       `json:"saving_throws"`
+	SkillBonuses           map[string]interface{} `json:"skill_bonuses"`
+	PassivePerception      int32                  `json:"passive_perception"`
+	BlindsightRange        float32                `json:"blindsight_range"`
+	DarkvisionRange        float32                `json:"darkvision_range"`
+	TremorsenseRange       float32                `json:"tremorsense_range"`
+	TruesightRange         float32                `json:"truesight_range"`
+	Languages              struct {
+		AsString string `json:"as_string"`
+	} `json:"languages"`
+	ResistancesAndImmunities struct {
+		DamageImmunitiesDisplay      string `json:"damage_immunities_display"`
+		DamageResistancesDisplay     string `json:"damage_resistances_display"`
+		DamageVulnerabilitiesDisplay string `json:"damage_vulnerabilities_display"`
+		ConditionImmunitiesDisplay   string `json:"condition_immunities_display"`
+	} `json:"resistances_and_immunities"`
+	Actions              []creatureActionV2 `json:"actions"`
+	Traits               []creatureTraitV2  `json:"traits"`
+	LegendaryDescription string             `json:"legendary_desc"`
+	Environments         []open5e.Named     `json:"environments"`
+}
+
+type creatureActionV2 struct {
+	Name                string `json:"name"`
+	Desc                string `json:"desc"`
+	ActionType          string `json:"action_type"`
+	LegendaryActionCost int32  `json:"legendary_action_cost"`
+	Attacks             []struct {
+		ToHitMod *int32 `json:"to_hit_mod"`
+	} `json:"attacks"`
+}
+
+type creatureTraitV2 struct {
+	Name string `json:"name"`
+	Desc string `json:"desc"`
+}
+
+func decodeMonsterV2(raw json.RawMessage) (MonsterImport, error) {
+	var creature creatureV2
+	err := json.Unmarshal(raw, &creature)
+	if err != nil {
+		return MonsterImport{}, err
+	}
+
+	cr, err := creature.ChallengeRatingDecimal.Float64()
+	if err != nil {
+		return MonsterImport{}, fmt.Errorf("bad challenge_rating_decimal for %s: %w", creature.Key, err)
+	}
+
+	// v2 reports the speed's unit alongside the speeds themselves
+	speed := map[string]interface{}{}
+	for movement, value := range creature.Speed {
+		if movement != "unit" {
+			speed[movement] = value
+		}
+	}
+
+	// saves which aren't listed stay nil, as they are null in v1
+	save := func(ability string) *int32 {
+		if bonus, ok := creature.SavingThrows[ability]; ok {
+			return &bonus
+		}
+		return nil
+	}
+
+	monster := MonsterImport{
+		Alignment:             creature.Alignment,
+		ArmorClass:            creature.ArmorClass,
+		ArmorDescription:      creature.ArmorDetail,
+		ChallengeRating:       float32(cr),
+		ChallengeRatingText:   creature.ChallengeRatingText,
+		Charisma:              creature.AbilityScores["charisma"],
+		CharismaSave:          save("charisma"),
+		ConditionImmunities:   creature.ResistancesAndImmunities.ConditionImmunitiesDisplay,
+		Constitution:          creature.AbilityScores["constitution"],
+		ConstitutionSave:      save("constitution"),
+		DamageImmunities:      creature.ResistancesAndImmunities.DamageImmunitiesDisplay,
+		DamageResistances:     creature.ResistancesAndImmunities.DamageResistancesDisplay,
+		DamageVulnerabilities: creature.ResistancesAndImmunities.DamageVulnerabilitiesDisplay,
+		Description:           creature.Desc,
+		Dexterity:             creature.AbilityScores["dexterity"],
+		DexteritySave:         save("dexterity"),
+		DocumentSlug:          creature.Document.Key,
+		Group:                 creature.Group,
+		HP:                    creature.HitPoints,
+		HitDice:               creature.HitDice,
+		Intelligence:          creature.AbilityScores["intelligence"],
+		IntelligenceSave:      save("intelligence"),
+		Languages:             creature.Languages.AsString,
+		LegendaryDescription:  creature.LegendaryDescription,
+		Name:                  creature.Name,
+		Senses:                sensesV2(creature),
+		Size:                  creature.Size.Name,
+		Skills:                creature.SkillBonuses,
+		Slug:                  creature.Key,
+		Speed:                 speed,
+		Strength:              creature.AbilityScores["strength"],
+		StrengthSave:          save("strength"),
+		Subtype:               creature.Subcategory,
+		Type:                  creature.Type.Name,
+		Wisdom:                creature.AbilityScores["wisdom"],
+		WisdomSave:            save("wisdom"),
+	}
+	if perception, ok := creature.SkillBonuses["perception"].(float64); ok {
+		monster.Perception = int32(perception)
+	}
+
+	for _, action := range creature.Actions {
+		entry := map[string]interface{}{"name": action.Name, "desc": action.Desc}
+		if len(action.Attacks) > 0 && action.Attacks[0].ToHitMod != nil {
+			entry["attack_bonus"] = *action.Attacks[0].ToHitMod
+		}
+		switch action.ActionType {
+		case "BONUS_ACTION":
+			monster.BonusActions = append(monster.BonusActions, entry)
+		case "REACTION":
+			monster.Reactions = append(monster.Reactions, entry)
+		case "LEGENDARY_ACTION":
+			// v1 keeps the cost in the action's name
+			if action.LegendaryActionCost > 1 {
+				entry["name"] = fmt.Sprintf("%s (Costs %d Actions)", action.Name, action.LegendaryActionCost)
+			}
+			monster.LegendaryActions = append(monster.LegendaryActions, entry)
+		default:
+			monster.Actions = append(monster.Actions, entry)
+		}
+	}
+
+	for _, trait := range creature.Traits {
+		monster.SpecialAbilities = append(monster.SpecialAbilities,
+			map[string]interface{}{"name": trait.Name, "desc": trait.Desc})
+	}
+
+	for _, environment := range creature.Environments {
+		monster.Environments = append(monster.Environments, environment.Name)
+	}
+
+	return monster, nil
+}
+
+// v2 splits senses into one range per sense, so rebuild the v1 string,
+// e.g. "blindsight 60 ft., darkvision 120 ft., passive Perception 21"
+func sensesV2(creature creatureV2) string {
+	ranges := map[string]float32{
+		"blindsight":  creature.BlindsightRange,
+		"darkvision":  creature.DarkvisionRange,
+		"tremorsense": creature.TremorsenseRange,
+		"truesight":   creature.TruesightRange,
+	}
+	var senses []string
+	for sense, distance := range ranges {
+		if distance > 0 {
+			senses = append(senses, fmt.Sprintf("%s %g ft.", sense, distance))
+		}
+	}
+	sort.Strings(senses)
+	senses = append(senses, fmt.Sprintf("passive Perception %d", creature.PassivePerception))
+	return strings.Join(senses, ", ")
+}
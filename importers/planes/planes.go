@@ -0,0 +1,109 @@
+package planes
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"open5e_importer/open5e"
+)
+
+// Import fetches every plane from the Open5e API and writes them to db,
+// returning the number of planes imported.
+func Import(db *sqlx.DB, client *open5e.Client, version open5e.Version) (int, error) {
+	return open5e.Import(client, planeResource, version, func(page []PlaneImport) error {
+		return writePlanesToDB(db, page)
+	})
+}
+
+type PlaneImport struct {
+	Name         string `json:"name" db:"name"`
+	Slug         string `json:"slug" db:"slug"`
+	Description  string `json:"desc" db:"description"`
+	Parent       string `json:"parent" db:"parent"`
+	ParentSlug   string `json:"-" db:"parent_slug"`
+	DocumentSlug string `json:"document__slug" db:"document_slug"`
+}
+
+// json keys of v1 results whose PlaneImport field isn't named after them
+var planeAliases = map[string]string{
+	"desc": "Description",
+}
+
+// v2 doesn't serve planes, so importing them from it fails with
+// open5e.ErrUnavailable.
+var planeResource = open5e.Resource[PlaneImport]{
+	V1Path:   "planes",
+	DecodeV1: decodePlaneV1,
+}
+
+// v1 gives a plane's parent by name, so its slug is made from the name
+// the way v1 makes slugs.
+func decodePlaneV1(raw json.RawMessage) (PlaneImport, error) {
+	plane, err := open5e.DecodeV1[PlaneImport](planeAliases)(raw)
+	if plane.Parent != "" {
+		plane.ParentSlug = open5e.Slugify(plane.Parent)
+	}
+	return plane, err
+}
+
+// writes the planes to plane_imports, replacing the row of a plane
+// imported before by its slug.
+func writePlanesToDB(db *sqlx.DB, planes []PlaneImport) error {
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS plane_imports (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT,
+			slug TEXT UNIQUE,
+			description TEXT,
+			parent TEXT,
+			parent_slug TEXT,
+			document_slug TEXT REFERENCES documents(slug)
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create plane_imports: %w", err)
+	}
+
+	for idx := range planes {
+		query := `
+			INSERT INTO plane_imports (
+				name, slug, description, parent, parent_slug,
+				document_slug
+			)
+			VALUES
+			(?, ?, ?, ?, ?, ?)
+			ON CONFLICT(slug) DO UPDATE SET
+				name = excluded.name,
+				description = excluded.description,
+				parent = excluded.parent,
+				parent_slug = excluded.parent_slug,
+				document_slug = excluded.document_slug
+		`
+		plane := planes[idx]
+		var parentSlug interface{}
+		if plane.ParentSlug != "" {
+			parentSlug = plane.ParentSlug
+		}
+		_, err = db.Exec(query, plane.Name, plane.Slug, plane.Description, plane.Parent, parentSlug,
+			plane.DocumentSlug)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func convertJsonToPlaneImports(jsonData []byte, version open5e.Version) ([]PlaneImport, string) {
+	planes, nextUrl, err := open5e.DecodePage(planeResource, version, jsonData)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if nextUrl == "" {
+		return planes, "DONE"
+	}
+	return planes, nextUrl
+}
@@ -0,0 +1,67 @@
+package planes
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/open5e"
+)
+
+func TestImportPlanes(t *testing.T) {
+	err := os.Remove("../../sql_database/planes_test.db")
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	db, err := sqlx.Open("sqlite3", "../../sql_database/planes_test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	planes, _ := convertJsonToPlaneImports(data, open5e.V1)
+	// importing twice replaces the rows rather than adding to them
+	for i := 0; i < 2; i++ {
+		if err := writePlanesToDB(db, planes); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the planes beyond the material are found through their parent's
+	// slug, and the top level planes have none
+	var hierarchy []string
+	err = db.Select(&hierarchy, `
+		SELECT COALESCE(parent.slug, '-') || ' ' || plane.slug
+		FROM plane_imports plane
+		LEFT JOIN plane_imports parent ON parent.slug = plane.parent_slug
+		ORDER BY plane.id
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"- the-material-plane",
+		"- beyond-the-material",
+		"beyond-the-material planar-travel",
+		"beyond-the-material transitive-planes",
+		"beyond-the-material inner-planes",
+		"beyond-the-material outer-planes",
+		"beyond-the-material demiplanes",
+	}
+	if !reflect.DeepEqual(hierarchy, want) {
+		t.Errorf("planes = %q, want %q", hierarchy, want)
+	}
+}
+
+func TestPlanesUnavailableInV2(t *testing.T) {
+	if _, err := planeResource.Url(open5e.V2); !errors.Is(err, open5e.ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable for planes in v2, got %v", err)
+	}
+}
@@ -0,0 +1,320 @@
+package races
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// abilities in the order of an ability array
+var abilityOrder = []string{"strength", "dexterity", "constitution", "intelligence", "wisdom", "charisma"}
+
+// AbilityIncrease is a fixed ability score increase, e.g. Constitution +2.
+type AbilityIncrease struct {
+	Ability string
+	Value   int32
+}
+
+// AbilityChoice is an increase of Value to Choose different abilities,
+// picked from Options, e.g. the half-elf's "two other ability scores of
+// your choice increase by 1".
+type AbilityChoice struct {
+	Choose  int32
+	Value   int32
+	Options []string
+}
+
+// RaceAsi is a race's, or a subrace's, ability score increases.
+type RaceAsi struct {
+	Fixed   []AbilityIncrease
+	Choices []AbilityChoice
+}
+
+var (
+	asiClause = regexp.MustCompile(`(?i)\.\s*|,?\s+and\s+`)
+	asiEach   = regexp.MustCompile(`^your ability scores each increase by (\d+)$`)
+	asiFixed  = regexp.MustCompile(`^your (\w+) score increases by (\d+)$`)
+	asiEither = regexp.MustCompile(`^(?:you can choose to increase )?(?:either )?your (\w+) or (\w+) score(?: increases)? by (\d+)$`)
+	asiAny    = regexp.MustCompile(`^(\w+) (other |different )?ability scores? of your choice(?:, other than (\w+),)? increases? by (\d+)$`)
+)
+
+// ParseAsi reads the increases of a race's asi list and its asi_desc
+// paragraph, and reports where they disagree, or where either isn't
+// understood.  the list has no way to say "Strength or Dexterity", or to
+// rule an ability out, so the description is taken when all of it is
+// understood, and the list otherwise.
+func ParseAsi(asi []interface{}, description string) (RaceAsi, []string) {
+	listed, problems := asiFromList(asi)
+	described, descProblems := asiFromDescription(description)
+	problems = append(problems, descProblems...)
+
+	if len(descProblems) > 0 || described.empty() {
+		return listed, problems
+	}
+	if !listed.empty() && listed.String() != described.String() {
+		problems = append(problems, fmt.Sprintf("asi is %s, description says %s", listed, described))
+	}
+	return described, problems
+}
+
+// asiFromList reads an asi list, e.g. [{"attributes":["Constitution"],
+// "value":2}].  runs of "Any" or "Other" with the same value are a choice
+// of that many abilities, "Other" ones ruling out the fixed increases.
+func asiFromList(list []interface{}) (RaceAsi, []string) {
+	var asi RaceAsi
+	var problems []string
+	var other []bool
+	for _, entry := range list {
+		obj, ok := entry.(map[string]interface{})
+		if !ok {
+			problems = append(problems, fmt.Sprintf("asi entry %v not understood", entry))
+			continue
+		}
+		attributes, _ := obj["attributes"].([]interface{})
+		number, _ := obj["value"].(float64)
+		value := int32(number)
+
+		var names []string
+		for _, attribute := range attributes {
+			name, _ := attribute.(string)
+			names = append(names, strings.ToLower(name))
+		}
+		switch {
+		case len(names) == 1 && (names[0] == "any" || names[0] == "other"):
+			isOther := names[0] == "other"
+			last := len(asi.Choices) - 1
+			if last >= 0 && other[last] == isOther && asi.Choices[last].Value == value {
+				asi.Choices[last].Choose++
+				continue
+			}
+			asi.Choices = append(asi.Choices, AbilityChoice{Choose: 1, Value: value})
+			other = append(other, isOther)
+		case len(names) == 1 && contains(abilityOrder, names[0]):
+			asi.Fixed = append(asi.Fixed, AbilityIncrease{names[0], value})
+		case len(names) > 1 && allAbilities(names):
+			asi.Choices = append(asi.Choices, AbilityChoice{Choose: 1, Value: value, Options: names})
+			other = append(other, false)
+		default:
+			problems = append(problems, fmt.Sprintf("asi entry %v not understood", entry))
+		}
+	}
+
+	for i := range asi.Choices {
+		if asi.Choices[i].Options == nil {
+			asi.Choices[i].Options = asi.abilitiesExcept(other[i], "")
+		}
+	}
+	return asi, problems
+}
+
+// asiFromDescription reads an asi_desc paragraph, e.g. "**_Ability Score
+// Increase._** Your Charisma score increases by 2, and two other ability
+// scores of your choice increase by 1.", a clause at a time.
+func asiFromDescription(description string) (RaceAsi, []string) {
+	var asi RaceAsi
+	var problems []string
+	text := strings.ToLower(stripLabel(description))
+	for _, clause := range asiClause.Split(text, -1) {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+		if m := asiEach.FindStringSubmatch(clause); m != nil {
+			for _, ability := range abilityOrder {
+				asi.Fixed = append(asi.Fixed, AbilityIncrease{ability, atoi32(m[1])})
+			}
+			continue
+		}
+		if m := asiFixed.FindStringSubmatch(clause); m != nil && contains(abilityOrder, m[1]) {
+			asi.Fixed = append(asi.Fixed, AbilityIncrease{m[1], atoi32(m[2])})
+			continue
+		}
+		if m := asiEither.FindStringSubmatch(clause); m != nil && allAbilities(m[1:3]) {
+			asi.Choices = append(asi.Choices, AbilityChoice{
+				Choose:  1,
+				Value:   atoi32(m[3]),
+				Options: []string{m[1], m[2]},
+			})
+			continue
+		}
+		if m := asiAny.FindStringSubmatch(clause); m != nil && countWord(m[1]) != 0 &&
+			(m[3] == "" || contains(abilityOrder, m[3])) {
+			asi.Choices = append(asi.Choices, AbilityChoice{
+				Choose:  countWord(m[1]),
+				Value:   atoi32(m[4]),
+				Options: asi.abilitiesExcept(m[2] == "other ", m[3]),
+			})
+			continue
+		}
+		problems = append(problems, fmt.Sprintf("description %q not understood", clause))
+	}
+	return asi, problems
+}
+
+// abilitiesExcept lists the abilities, less those with a fixed increase
+// when other is set, and less except.
+func (asi RaceAsi) abilitiesExcept(other bool, except string) []string {
+	var options []string
+	for _, ability := range abilityOrder {
+		if ability == except || other && asi.increases(ability) {
+			continue
+		}
+		options = append(options, ability)
+	}
+	return options
+}
+
+func (asi RaceAsi) increases(ability string) bool {
+	for _, increase := range asi.Fixed {
+		if increase.Ability == ability {
+			return true
+		}
+	}
+	return false
+}
+
+func (asi RaceAsi) empty() bool {
+	return len(asi.Fixed) == 0 && len(asi.Choices) == 0
+}
+
+// String describes the increases the same way whichever order they were
+// given in, e.g. "charisma +2, 2 of dexterity/strength/... +1".
+func (asi RaceAsi) String() string {
+	fixed := append([]AbilityIncrease(nil), asi.Fixed...)
+	sort.SliceStable(fixed, func(i, j int) bool {
+		return abilityIndex(fixed[i].Ability) < abilityIndex(fixed[j].Ability)
+	})
+	var parts []string
+	for _, increase := range fixed {
+		parts = append(parts, fmt.Sprintf("%s +%d", increase.Ability, increase.Value))
+	}
+	for _, choice := range asi.Choices {
+		parts = append(parts, fmt.Sprintf("%d of %s +%d", choice.Choose, strings.Join(choice.Options, "/"), choice.Value))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Apply adds the increases to base, an ability array in the usual
+// Strength to Charisma order.  picks are the abilities picked for each of
+// the choices, in order.
+func (asi RaceAsi) Apply(base [6]int32, picks [][]string) ([6]int32, error) {
+	scores := base
+	for _, increase := range asi.Fixed {
+		scores[abilityIndex(increase.Ability)] += increase.Value
+	}
+
+	if len(picks) != len(asi.Choices) {
+		return base, fmt.Errorf("%d choices picked, want %d", len(picks), len(asi.Choices))
+	}
+	for i, choice := range asi.Choices {
+		if int32(len(picks[i])) != choice.Choose {
+			return base, fmt.Errorf("%d abilities picked for choice %d, want %d", len(picks[i]), i+1, choice.Choose)
+		}
+		for j, ability := range picks[i] {
+			ability = strings.ToLower(ability)
+			if !contains(choice.Options, ability) {
+				return base, fmt.Errorf("%s can't be picked for choice %d", ability, i+1)
+			}
+			for _, earlier := range picks[i][:j] {
+				if strings.EqualFold(earlier, ability) {
+					return base, fmt.Errorf("%s picked twice for choice %d", ability, i+1)
+				}
+			}
+			scores[abilityIndex(ability)] += choice.Value
+		}
+	}
+	return scores, nil
+}
+
+func abilityIndex(ability string) int {
+	for i, a := range abilityOrder {
+		if a == ability {
+			return i
+		}
+	}
+	return -1
+}
+
+func allAbilities(names []string) bool {
+	for _, name := range names {
+		if !contains(abilityOrder, name) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+var numberWords = map[string]int32{"one": 1, "two": 2, "three": 3}
+
+func countWord(word string) int32 {
+	if n, ok := numberWords[word]; ok {
+		return n
+	}
+	return 0
+}
+
+func createRaceAsiTables(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS race_asi (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			race_slug TEXT,
+			choice INTEGER,
+			choose INTEGER,
+			ability TEXT,
+			value INTEGER
+		);
+		CREATE TABLE IF NOT EXISTS race_asi_mismatches (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			race_slug TEXT,
+			problem TEXT
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create race_asi table: %w", err)
+	}
+	return nil
+}
+
+// writes a row to race_asi for each fixed increase, with choice and choose
+// NULL, and for each option of a choice, numbered from 1, and what
+// ParseAsi reports to race_asi_mismatches.  subraces are written under
+// their own slug.
+func writeRaceAsiToDB(db sqlx.Execer, slug string, list []interface{}, description string) error {
+	asi, problems := ParseAsi(list, description)
+	for _, increase := range asi.Fixed {
+		_, err := db.Exec(`INSERT INTO race_asi (race_slug, choice, choose, ability, value) VALUES (?, ?, ?, ?, ?);`,
+			slug, nil, nil, increase.Ability, increase.Value)
+		if err != nil {
+			return fmt.Errorf("failed to insert row into race_asi table: %w", err)
+		}
+	}
+	for i, choice := range asi.Choices {
+		for _, option := range choice.Options {
+			_, err := db.Exec(`INSERT INTO race_asi (race_slug, choice, choose, ability, value) VALUES (?, ?, ?, ?, ?);`,
+				slug, i+1, choice.Choose, option, choice.Value)
+			if err != nil {
+				return fmt.Errorf("failed to insert row into race_asi table: %w", err)
+			}
+		}
+	}
+
+	for _, problem := range problems {
+		_, err := db.Exec(`INSERT INTO race_asi_mismatches (race_slug, problem) VALUES (?, ?);`, slug, problem)
+		if err != nil {
+			return fmt.Errorf("failed to insert row into race_asi_mismatches table: %w", err)
+		}
+	}
+	return nil
+}
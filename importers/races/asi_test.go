@@ -0,0 +1,81 @@
+package races
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"open5e_importer/open5e"
+)
+
+func TestParseAsi(t *testing.T) {
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	races, _ := convertJsonToRaceImports(data, open5e.V1)
+	type parsed struct {
+		asi      string
+		problems int
+	}
+	got := map[string]parsed{}
+	for _, race := range races {
+		asi, problems := ParseAsi(race.Asi, race.AsiDescription)
+		got[race.Slug] = parsed{asi.String(), len(problems)}
+		for _, subrace := range raceSubraces(race) {
+			asi, problems := ParseAsi(subrace.Asi, subrace.AsiDescription)
+			got[subrace.Slug] = parsed{asi.String(), len(problems)}
+		}
+	}
+
+	tests := []struct {
+		slug     string
+		asi      string
+		problems int
+	}{
+		{"dwarf", "constitution +2", 0},
+		{"human", "strength +1, dexterity +1, constitution +1, intelligence +1, wisdom +1, charisma +1", 0},
+		// the order they're given in doesn't matter
+		{"tiefling", "intelligence +1, charisma +2", 0},
+		{"half-elf", "charisma +2, 2 of strength/dexterity/constitution/intelligence/wisdom +1", 0},
+		{"gearforged", "2 of strength/dexterity/constitution/intelligence/wisdom/charisma +1", 0},
+		// the list leaves out the choice
+		{"erina", "dexterity +2, 1 of wisdom/charisma +1", 1},
+		// the list can't say "or"
+		{"delver", "1 of strength/dexterity +1", 1},
+		// nor "other than Constitution"
+		{"humanhalf-elf-heritage", "1 of strength/dexterity/intelligence/wisdom/charisma +2", 1},
+		// "Your Wisdon score", so the list is taken
+		{"dwarf-heritage", "wisdom +2", 1},
+	}
+	for _, test := range tests {
+		if got[test.slug] != (parsed{test.asi, test.problems}) {
+			t.Errorf("%s: got %q with %d problems, want %q with %d",
+				test.slug, got[test.slug].asi, got[test.slug].problems, test.asi, test.problems)
+		}
+	}
+}
+
+func TestApplyAsi(t *testing.T) {
+	halfElf, _ := ParseAsi(nil, "**_Ability Score Increase._** Your Charisma score increases by 2, and two other ability scores of your choice increase by 1.")
+	base := [6]int32{15, 14, 13, 12, 10, 8}
+
+	scores, err := halfElf.Apply(base, [][]string{{"Dexterity", "Constitution"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [6]int32{15, 15, 14, 12, 10, 10}; scores != want {
+		t.Errorf("Apply = %v, want %v", scores, want)
+	}
+
+	for _, picks := range [][][]string{
+		nil,
+		{{"dexterity"}},
+		{{"dexterity", "dexterity"}},
+		{{"dexterity", "charisma"}},
+		{{"dexterity", "luck"}},
+	} {
+		if _, err := halfElf.Apply(base, picks); err == nil {
+			t.Errorf("Apply with picks %q succeeded", picks)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package races
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"open5e_importer/open5e"
+)
+
+// Import fetches every race from the Open5e API and writes them to db,
+// returning the number of races imported.  v2 subspecies reference their
+// race, which may be on a later page, so they are written once every race
+// has been.
+func Import(db *sqlx.DB, client *open5e.Client, version open5e.Version) (int, error) {
+	var subspecies []RaceImport
+	count, err := open5e.Import(client, raceResource, version, func(page []RaceImport) error {
+		var races []RaceImport
+		for _, race := range page {
+			if race.SubraceOf != "" {
+				subspecies = append(subspecies, race)
+			} else {
+				races = append(races, race)
+			}
+		}
+		return writeRacesToDB(db, races)
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, writeRacesToDB(db, subspecies)
+}
+
+type RaceImport struct {
+	Age              string        `json:"age" db:"age"`
+	Alignment        string        `json:"alignment" db:"alignment"`
+	Asi              []interface{} `json:"asi" db:"asi"`
+	AsiDescription   string        `json:"asi_desc" db:"asi_description"`
+	Description      string        `json:"desc" db:"description"`
+	DocumentSlug     string        `json:"document__slug" db:"document_slug"`
+	Languages        string        `json:"languages" db:"languages"`
+	Name             string        `json:"name" db:"name"`
+	Size             string        `json:"size" db:"size"`
+	SizeRaw          string        `json:"size_raw" db:"size_raw"`
+	Slug             string        `json:"slug" db:"slug"`
+	Speed            interface{}   `json:"speed" db:"speed"`
+	SpeedDescription string        `json:"speed_desc" db:"speed_description"`
+	Subraces         []interface{} `json:"subraces" db:"subraces"`
+	Traits           string        `json:"traits" db:"traits"`
+	Vision           string        `json:"vision" db:"vision"`
+	// v2 serves subraces as species of their own, which are subspecies of
+	// their race.
+	SubraceOf string `json:"-" db:"-"`
+}
+
+// json keys of v1 results whose RaceImport field isn't named after them
+var raceAliases = map[string]string{
+	"speed_desc": "SpeedDescription",
+	"asi_desc":   "AsiDescription",
+}
+
+var raceResource = open5e.Resource[RaceImport]{
+	V1Path:   "races",
+	DecodeV1: open5e.DecodeV1[RaceImport](raceAliases),
+	V2Path:   "species",
+	DecodeV2: decodeRaceV2,
+}
+
+func convertJsonToRaceImports(jsonData []byte, version open5e.Version) ([]RaceImport, string) {
+	races, nextUrl, err := open5e.DecodePage(raceResource, version, jsonData)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if nextUrl == "" {
+		return races, "DONE"
+	}
+	return races, nextUrl
+}
+
+func writeRacesToDB(db *sqlx.DB, races []RaceImport) error {
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS race_imports (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			age TEXT,
+			alignment TEXT,
+			asi TEXT,
+			asi_description TEXT,
+			description TEXT,
+			document_slug TEXT REFERENCES documents(slug),
+			languages TEXT,
+			name TEXT,
+			size TEXT,
+			size_raw TEXT,
+			slug TEXT UNIQUE,
+			speed TEXT,
+			speed_description TEXT,
+			traits TEXT,
+			vision TEXT,
+			walk_speed INTEGER,
+			swim_speed INTEGER,
+			fly_speed INTEGER,
+			climb_speed INTEGER,
+			burrow_speed INTEGER,
+			hover BOOLEAN,
+			darkvision INTEGER,
+			maturity_age INTEGER,
+			lifespan INTEGER,
+			min_height INTEGER,
+			max_height INTEGER,
+			average_weight INTEGER
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create race_imports table: %w", err)
+	}
+	for _, create := range []func(*sqlx.DB) error{
+		createRaceLanguagesTable,
+		createSubraceTables,
+		createRaceAsiTables,
+		createRaceTraitsTable,
+	} {
+		err = create(db)
+		if err != nil {
+			return err
+		}
+	}
+
+	// v2 subspecies are written after the races, which they reference
+	ordered := make([]RaceImport, 0, len(races))
+	for _, race := range races {
+		if race.SubraceOf == "" {
+			ordered = append(ordered, race)
+		}
+	}
+	for _, race := range races {
+		if race.SubraceOf != "" {
+			ordered = append(ordered, race)
+		}
+	}
+
+	for _, race := range ordered {
+		// a race and its child rows, with the subraces it lists, are
+		// replaced together, so a re-import never leaves rows of the
+		// previous import behind.  v2 serves subraces as races of their
+		// own, which are replaced alone.
+		tx, err := db.Beginx()
+		if err != nil {
+			return err
+		}
+		if race.SubraceOf != "" {
+			err = writeSubraceToDB(tx, Subrace{
+				RaceSlug:       race.SubraceOf,
+				Slug:           race.Slug,
+				Name:           race.Name,
+				Description:    race.Description,
+				DocumentSlug:   race.DocumentSlug,
+				AsiDescription: race.AsiDescription,
+				Traits:         race.Traits,
+			})
+		} else {
+			err = writeRaceToDB(tx, race)
+		}
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("%s: %w", race.Slug, err)
+		}
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writes the race to race_imports and its other tables, with the subraces
+// it lists, after deleting what an earlier import wrote for it.  the
+// race_imports row is updated in place, so subraces which reference it
+// aren't disturbed.
+func writeRaceToDB(tx *sqlx.Tx, race RaceImport) error {
+	err := deleteRaceFromDB(tx, race.Slug)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO race_imports (
+		age, alignment, asi, asi_description, description, document_slug, languages, name,
+		size, size_raw, slug, speed, speed_description, traits, vision,
+		walk_speed, swim_speed, fly_speed, climb_speed, burrow_speed, hover, darkvision,
+		maturity_age, lifespan, min_height, max_height, average_weight) VALUES
+		(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(slug) DO UPDATE SET
+			age = excluded.age, alignment = excluded.alignment, asi = excluded.asi,
+			asi_description = excluded.asi_description, description = excluded.description,
+			document_slug = excluded.document_slug, languages = excluded.languages,
+			name = excluded.name, size = excluded.size, size_raw = excluded.size_raw,
+			speed = excluded.speed, speed_description = excluded.speed_description,
+			traits = excluded.traits, vision = excluded.vision, walk_speed = excluded.walk_speed,
+			swim_speed = excluded.swim_speed, fly_speed = excluded.fly_speed,
+			climb_speed = excluded.climb_speed, burrow_speed = excluded.burrow_speed,
+			hover = excluded.hover, darkvision = excluded.darkvision,
+			maturity_age = excluded.maturity_age, lifespan = excluded.lifespan,
+			min_height = excluded.min_height, max_height = excluded.max_height,
+			average_weight = excluded.average_weight;`
+
+	asi, err := json.Marshal(race.Asi)
+	if err != nil {
+		return fmt.Errorf("failed to marshal asi: %w", err)
+	}
+
+	speed, err := json.Marshal(race.Speed)
+	if err != nil {
+		return fmt.Errorf("failed to marshal speed: %w", err)
+	}
+
+	typedSpeed := raceSpeed(race)
+
+	// speeds and facts the race doesn't give are NULL
+	facts := raceFacts(race)
+
+	_, err = tx.Exec(
+		query, stripLabel(race.Age), stripLabel(race.Alignment), asi, race.AsiDescription, race.Description,
+		race.DocumentSlug, race.Languages, race.Name, stripLabel(race.Size), race.SizeRaw, race.Slug, speed,
+		race.SpeedDescription, race.Traits, stripLabel(race.Vision), typedSpeed.Walk, typedSpeed.Swim,
+		typedSpeed.Fly, typedSpeed.Climb, typedSpeed.Burrow, typedSpeed.Hover, orNull(facts.Darkvision),
+		orNull(facts.MaturityAge), orNull(facts.Lifespan), orNull(facts.MinHeight),
+		orNull(facts.MaxHeight), orNull(facts.AverageWeight))
+	if err != nil {
+		return fmt.Errorf("failed to insert row into race_imports table: %w", err)
+	}
+
+	err = writeRaceLanguagesToDB(tx, race)
+	if err != nil {
+		return err
+	}
+	err = writeRaceAsiToDB(tx, race.Slug, race.Asi, race.AsiDescription)
+	if err != nil {
+		return err
+	}
+	err = writeRaceTraitsToDB(tx, race.Slug, race.Traits)
+	if err != nil {
+		return err
+	}
+	for _, subrace := range raceSubraces(race) {
+		err = writeSubraceToDB(tx, subrace)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deletes every row written for the race in the other race tables, so it
+// can be written again.  its subraces are left to be replaced by slug when
+// they are written, as v2 serves them apart from the race.
+func deleteRaceFromDB(db sqlx.Execer, raceSlug string) error {
+	for _, query := range []string{
+		`DELETE FROM race_languages WHERE race_slug = ?`,
+		`DELETE FROM race_asi WHERE race_slug = ?`,
+		`DELETE FROM race_asi_mismatches WHERE race_slug = ?`,
+		`DELETE FROM race_traits WHERE race_slug = ?`,
+	} {
+		_, err := db.Exec(query, raceSlug)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
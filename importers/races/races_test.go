@@ -0,0 +1,229 @@
+package races
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/open5e"
+)
+
+func TestImportRaces(t *testing.T) {
+	err := os.Remove("../../sql_database/races_test.db")
+	if err != nil {
+		if os.IsNotExist(err) {
+			// don't worry about it!
+		} else {
+			log.Fatal(err)
+		}
+
+	}
+
+	db, err := sqlx.Open("sqlite3", "../../sql_database/races_test.db")
+	if err != nil {
+		log.Fatalf("Failed to open sqlite db: %v", err)
+	}
+	if err != nil {
+		if os.IsNotExist(err) {
+			// don't worry about it!
+		} else {
+			t.Fatal(err)
+		}
+	}
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	monsters, _ := convertJsonToRaceImports(data, open5e.V1)
+	if err := writeRacesToDB(db, monsters); err != nil {
+		t.Fatal(err)
+	}
+
+	// speeds and darkvision a race doesn't have are NULL
+	var stats []string
+	err = db.Select(&stats, `
+		SELECT slug || ' ' || COALESCE(walk_speed, '-') || ' ' || COALESCE(fly_speed, '-') || ' ' || COALESCE(darkvision, '-')
+		FROM race_imports WHERE slug IN ('dwarf', 'human') ORDER BY slug
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"dwarf 25 - 60", "human 30 - -"}; !reflect.DeepEqual(stats, want) {
+		t.Errorf("race stats = %q, want %q", stats, want)
+	}
+}
+
+func TestDecodeRacesV2(t *testing.T) {
+	v1Data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2Data, err := ioutil.ReadFile("./test_data/testdata_v2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1Races, _ := convertJsonToRaceImports(v1Data, open5e.V1)
+	v2Races, _ := convertJsonToRaceImports(v2Data, open5e.V2)
+	// subspecies come after their race, as subraces of it
+	if len(v2Races) != 4 {
+		t.Fatalf("expected 2 races and 2 subraces, got %d", len(v2Races))
+	}
+
+	var races []RaceImport
+	subraces := map[string]RaceImport{}
+	for _, race := range v2Races {
+		if race.SubraceOf != "" {
+			subraces[race.Slug] = race
+			continue
+		}
+		races = append(races, race)
+	}
+	for i, v2 := range races {
+		v1 := v1Races[i]
+		for _, sub := range raceSubraces(v1) {
+			v2Sub := subraces[sub.Slug]
+			if v2Sub.SubraceOf != v1.Slug || v2Sub.Name != sub.Name {
+				t.Errorf("unexpected subrace %q of %q, expected %q of %q", v2Sub.Slug, v2Sub.SubraceOf, sub.Slug, v1.Slug)
+			}
+			if v2Sub.AsiDescription != sub.AsiDescription || v2Sub.Traits != sub.Traits {
+				t.Errorf("%s: v1 and v2 differ:\n%q %q\n%q %q", sub.Slug,
+					sub.AsiDescription, sub.Traits, v2Sub.AsiDescription, v2Sub.Traits)
+			}
+		}
+		if v2.Slug != v1.Slug || v2.Name != v1.Name || v2.DocumentSlug != v1.DocumentSlug {
+			t.Errorf("unexpected race %q, expected %q", v2.Slug, v1.Slug)
+		}
+		for _, pair := range [][2]string{
+			{v1.AsiDescription, v2.AsiDescription},
+			{v1.Age, v2.Age},
+			{v1.Size, v2.Size},
+			{v1.SpeedDescription, v2.SpeedDescription},
+			{v1.Languages, v2.Languages},
+			{v1.Vision, v2.Vision},
+			{v1.Traits, v2.Traits},
+		} {
+			if pair[0] != pair[1] {
+				t.Errorf("%s: v1 and v2 differ:\n%q\n%q", v1.Slug, pair[0], pair[1])
+			}
+		}
+	}
+}
+
+func TestRaceStats(t *testing.T) {
+	race := RaceImport{
+		SpeedDescription: "**_Speed._** Your base walking speed is 30 feet, and you have a swimming speed of 30 feet.",
+		Vision:           "**_Superior Darkvision._** Your darkvision has a radius of 120 feet. You can see in dim light within 120 feet of you as if it were bright light.",
+		Languages:        "**_Languages._** You can speak, read, and write Common, Machine Speech (a whistling, clicking language), and one of the following: Abyssal, Infernal, or Void Speech.",
+	}
+	speed := raceSpeed(race)
+	if speed.Walk == nil || *speed.Walk != 30 || speed.Swim == nil || *speed.Swim != 30 || speed.Fly != nil {
+		t.Errorf("raceSpeed = %+v, want walk and swim 30 and no fly", speed)
+	}
+	if darkvision := raceDarkvision(race); darkvision != 120 {
+		t.Errorf("raceDarkvision = %d, want 120", darkvision)
+	}
+	want := []string{"Common", "Machine Speech (a whistling, clicking language)", "one of the following: Abyssal, Infernal, or Void Speech"}
+	if languages := raceLanguages(race); !reflect.DeepEqual(languages, want) {
+		t.Errorf("raceLanguages = %q, want %q", languages, want)
+	}
+}
+
+func TestReimportRaces(t *testing.T) {
+	err := os.Remove("../../sql_database/races_reimport_test.db")
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	// with foreign keys on, as the importer runs, so subraces must be
+	// written after their race and deleted before it
+	db, err := sqlx.Open("sqlite3", "../../sql_database/races_reimport_test.db?_foreign_keys=on")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// v1 races list their subraces, v2 serves them apart, here before the
+	// race they reference
+	var races []RaceImport
+	for _, fixture := range []struct {
+		file    string
+		version open5e.Version
+	}{{"./test_data/testdata.json", open5e.V1}, {"./test_data/testdata_v2.json", open5e.V2}} {
+		data, err := ioutil.ReadFile(fixture.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page, _ := convertJsonToRaceImports(data, fixture.version)
+		for i := len(page) - 1; i >= 0; i-- {
+			races = append(races, page[i])
+		}
+	}
+
+	// documents is owned by the documents importer, so only its slugs are
+	// written here
+	if _, err := db.Exec(`CREATE TABLE documents (slug TEXT PRIMARY KEY)`); err != nil {
+		t.Fatal(err)
+	}
+	for _, race := range races {
+		documents := []string{race.DocumentSlug}
+		for _, subrace := range raceSubraces(race) {
+			documents = append(documents, subrace.DocumentSlug)
+		}
+		for _, document := range documents {
+			if _, err := db.Exec(`INSERT OR IGNORE INTO documents (slug) VALUES (?)`, document); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	tables := []string{"race_imports", "race_languages", "subrace_imports", "race_asi",
+		"race_asi_mismatches", "race_traits"}
+	counts := func() []int {
+		var counts []int
+		for _, table := range tables {
+			var count int
+			if err := db.Get(&count, "SELECT COUNT(*) FROM "+table); err != nil {
+				t.Fatal(err)
+			}
+			counts = append(counts, count)
+		}
+		return counts
+	}
+	if err := writeRacesToDB(db, races); err != nil {
+		t.Fatal(err)
+	}
+	first := counts()
+	if err := writeRacesToDB(db, races); err != nil {
+		t.Fatal(err)
+	}
+	if second := counts(); !reflect.DeepEqual(first, second) {
+		t.Errorf("re-import changed the row counts of %v from %v to %v", tables, first, second)
+	}
+	for i, count := range first {
+		if count == 0 {
+			t.Errorf("nothing written to %s", tables[i])
+		}
+	}
+
+	// the v2 dwarf replaced the v1 one in place, and a dwarf which lists no
+	// subraces leaves the hill dwarf written before alone
+	var dwarf RaceImport
+	for _, race := range races {
+		if race.Slug == "dwarf" {
+			dwarf = race
+		}
+	}
+	dwarf.Subraces = nil
+	if err := writeRacesToDB(db, []RaceImport{dwarf}); err != nil {
+		t.Fatal(err)
+	}
+	var hillDwarf string
+	if err := db.Get(&hillDwarf, `SELECT race_slug FROM subrace_imports WHERE slug = 'hill-dwarf'`); err != nil {
+		t.Fatal(err)
+	}
+	if hillDwarf != "dwarf" {
+		t.Errorf("hill-dwarf is a subrace of %q, want dwarf", hillDwarf)
+	}
+}
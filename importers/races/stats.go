@@ -0,0 +1,146 @@
+package races
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/statblock"
+)
+
+var (
+	speedDescRe   = regexp.MustCompile(`(?i)\b(walking|swimming|flying|climbing|burrowing) speed (?:is|of|increases to) (\d+) feet`)
+	darkvisionRe  = regexp.MustCompile(`(?i)\bwithin (\d+) feet\b`)
+	languagesRe   = regexp.MustCompile(`(?i)speak, read, and write ([^.]*)`)
+	languageSplit = regexp.MustCompile(`\s*,\s*(?:and\s+)?|\s+and\s+`)
+)
+
+// raceSpeed types the race's speed.  v2 species only describe their speed,
+// "Your base walking speed is 25 feet.", so it is read from the
+// description when there is no speed object.  a subrace's "Your base
+// walking speed increases to 35 feet." is read the same way.
+func raceSpeed(race RaceImport) statblock.Speed {
+	if race.Speed != nil {
+		speed, err := statblock.ParseSpeed(race.Speed)
+		if err != nil {
+			fmt.Printf("Speed of %s not understood: %v\n", race.Slug, err)
+		}
+		return speed
+	}
+
+	var speed statblock.Speed
+	for _, m := range speedDescRe.FindAllStringSubmatch(race.SpeedDescription, -1) {
+		feet := atoi32(m[2])
+		switch strings.ToLower(m[1]) {
+		case "walking":
+			speed.Walk = &feet
+		case "swimming":
+			speed.Swim = &feet
+		case "flying":
+			speed.Fly = &feet
+		case "climbing":
+			speed.Climb = &feet
+		case "burrowing":
+			speed.Burrow = &feet
+		}
+	}
+	return speed
+}
+
+// raceDarkvision returns the range of the darkvision described by the
+// race's vision paragraph, or 0 for a race without it.
+func raceDarkvision(race RaceImport) int32 {
+	if !strings.Contains(strings.ToLower(race.Vision), "darkvision") {
+		return 0
+	}
+	if m := darkvisionRe.FindStringSubmatch(race.Vision); m != nil {
+		return atoi32(m[1])
+	}
+	return 0
+}
+
+// raceLanguages lists the languages in the race's languages paragraph,
+// "You can speak, read, and write Common and Dwarvish.".  choices such as
+// "one extra language of your choice" or "one of the following: Abyssal,
+// Infernal, or Void Speech" are kept whole, as written.
+func raceLanguages(race RaceImport) []string {
+	m := languagesRe.FindStringSubmatch(race.Languages)
+	if m == nil {
+		return nil
+	}
+	text, choice := m[1], ""
+	if colon := strings.Index(text, ":"); colon >= 0 {
+		start := 0
+		for _, sep := range languageSplit.FindAllStringIndex(text[:colon], -1) {
+			start = sep[0]
+		}
+		text, choice = text[:start], text[start:]
+		if sep := languageSplit.FindStringIndex(choice); sep != nil && sep[0] == 0 {
+			choice = choice[sep[1]:]
+		}
+	}
+
+	var languages []string
+	start := 0
+	for _, sep := range languageSplit.FindAllStringIndex(text, -1) {
+		// separators inside parentheses are part of a language's note
+		if strings.Count(text[:sep[0]], "(") > strings.Count(text[:sep[0]], ")") {
+			continue
+		}
+		languages = append(languages, strings.TrimSpace(text[start:sep[0]]))
+		start = sep[1]
+	}
+	languages = append(languages, strings.TrimSpace(text[start:]))
+	if choice != "" {
+		languages = append(languages, choice)
+	}
+
+	nonEmpty := languages[:0]
+	for _, language := range languages {
+		if language != "" {
+			nonEmpty = append(nonEmpty, language)
+		}
+	}
+	return nonEmpty
+}
+
+func atoi32(s string) int32 {
+	n := int32(0)
+	for _, r := range s {
+		n = n*10 + int32(r-'0')
+	}
+	return n
+}
+
+// orNull is n, or NULL for a number the race doesn't give.
+func orNull(n int32) interface{} {
+	if n == 0 {
+		return nil
+	}
+	return n
+}
+
+func createRaceLanguagesTable(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS race_languages (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			race_slug TEXT,
+			language TEXT
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create race_languages table: %w", err)
+	}
+	return nil
+}
+
+func writeRaceLanguagesToDB(db sqlx.Execer, race RaceImport) error {
+	for _, language := range raceLanguages(race) {
+		_, err := db.Exec(`INSERT INTO race_languages (race_slug, language) VALUES (?, ?);`, race.Slug, language)
+		if err != nil {
+			return fmt.Errorf("failed to insert row into race_languages table: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package races
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/statblock"
+)
+
+// Subrace is one of a race's subraces, e.g. the dwarf's "Hill Dwarf".  its
+// ability score increases and traits are on top of the race's own.
+type Subrace struct {
+	RaceSlug       string
+	Slug           string
+	Name           string
+	Description    string
+	DocumentSlug   string
+	Asi            []interface{}
+	AsiDescription string
+	Traits         string
+}
+
+// raceSubraces returns the subraces v1 lists on the race.
+func raceSubraces(race RaceImport) []Subrace {
+	var subraces []Subrace
+	for _, sub := range race.Subraces {
+		obj, ok := sub.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		subrace := Subrace{RaceSlug: race.Slug}
+		subrace.Slug, _ = obj["slug"].(string)
+		subrace.Name, _ = obj["name"].(string)
+		subrace.Description, _ = obj["desc"].(string)
+		subrace.DocumentSlug, _ = obj["document__slug"].(string)
+		subrace.Asi, _ = obj["asi"].([]interface{})
+		subrace.AsiDescription, _ = obj["asi_desc"].(string)
+		subrace.Traits, _ = obj["traits"].(string)
+		subraces = append(subraces, subrace)
+	}
+	return subraces
+}
+
+// subraceSpeed reads the speeds a subrace's traits give, e.g. "You have a
+// swimming speed of 25 feet." or "Your base walking speed increases to 35
+// feet.".  speeds it doesn't mention are left to the race.
+func subraceSpeed(subrace Subrace) statblock.Speed {
+	return raceSpeed(RaceImport{Slug: subrace.Slug, SpeedDescription: subrace.Traits})
+}
+
+// subraceDarkvision is the range of the darkvision a subrace's Darkvision
+// or Superior Darkvision trait gives, or 0 when it keeps the race's.  other
+// traits' ranges, such as "within 30 feet" of a mask, aren't darkvision.
+func subraceDarkvision(subrace Subrace) int32 {
+	for _, trait := range ParseTraits(subrace.Traits) {
+		name := strings.ToLower(trait.Name)
+		if name != "darkvision" && name != "superior darkvision" {
+			continue
+		}
+		if m := darkvisionRe.FindStringSubmatch(trait.Body); m != nil {
+			return atoi32(m[1])
+		}
+	}
+	return 0
+}
+
+// race_options has a row for each race a character can be, which is
+// either a subrace, merged with its race, or a race without subraces.
+// ability score increases are both lists of increases, and traits both
+// paragraphs.  a subrace's speeds and darkvision are the race's unless it
+// gives its own.
+func createSubraceTables(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS subrace_imports (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			race_slug TEXT REFERENCES race_imports(slug),
+			slug TEXT,
+			name TEXT,
+			description TEXT,
+			document_slug TEXT REFERENCES documents(slug),
+			asi TEXT,
+			asi_description TEXT,
+			traits TEXT,
+			walk_speed INTEGER,
+			swim_speed INTEGER,
+			fly_speed INTEGER,
+			climb_speed INTEGER,
+			burrow_speed INTEGER,
+			darkvision INTEGER
+		);
+		CREATE VIEW IF NOT EXISTS race_options AS
+			SELECT
+				r.slug AS slug, r.name AS name, r.slug AS race_slug, NULL AS subrace_slug,
+				r.document_slug AS document_slug, r.asi AS asi, r.asi_description AS asi_description,
+				r.traits AS traits, r.walk_speed AS walk_speed, r.swim_speed AS swim_speed,
+				r.fly_speed AS fly_speed, r.climb_speed AS climb_speed,
+				r.burrow_speed AS burrow_speed, r.hover AS hover, r.darkvision AS darkvision
+			FROM race_imports r
+			WHERE NOT EXISTS (SELECT 1 FROM subrace_imports s WHERE s.race_slug = r.slug)
+			UNION ALL
+			SELECT
+				s.slug, s.name, r.slug, s.slug, s.document_slug,
+				(SELECT json_group_array(json(value)) FROM (
+					SELECT value FROM json_each(r.asi)
+					UNION ALL
+					SELECT value FROM json_each(s.asi)
+				)),
+				r.asi_description || char(10) || char(10) || s.asi_description,
+				r.traits || char(10) || char(10) || s.traits,
+				COALESCE(s.walk_speed, r.walk_speed), COALESCE(s.swim_speed, r.swim_speed),
+				COALESCE(s.fly_speed, r.fly_speed), COALESCE(s.climb_speed, r.climb_speed),
+				COALESCE(s.burrow_speed, r.burrow_speed), r.hover,
+				COALESCE(s.darkvision, r.darkvision)
+			FROM subrace_imports s
+			JOIN race_imports r ON r.slug = s.race_slug;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create subrace_imports table: %w", err)
+	}
+	return nil
+}
+
+// writes the subrace to subrace_imports, with its ability score increases
+// and traits under its own slug, after deleting what an earlier import
+// wrote for it.
+func writeSubraceToDB(db sqlx.Execer, subrace Subrace) error {
+	for _, query := range []string{
+		`DELETE FROM race_asi WHERE race_slug = ?`,
+		`DELETE FROM race_asi_mismatches WHERE race_slug = ?`,
+		`DELETE FROM race_traits WHERE race_slug = ?`,
+		`DELETE FROM subrace_imports WHERE slug = ?`,
+	} {
+		_, err := db.Exec(query, subrace.Slug)
+		if err != nil {
+			return err
+		}
+	}
+
+	asi, err := json.Marshal(subrace.Asi)
+	if err != nil {
+		return fmt.Errorf("failed to marshal asi: %w", err)
+	}
+
+	// speeds and darkvision the subrace doesn't give are NULL
+	speed := subraceSpeed(subrace)
+
+	_, err = db.Exec(`
+		INSERT INTO subrace_imports (
+			race_slug, slug, name, description, document_slug, asi, asi_description, traits,
+			walk_speed, swim_speed, fly_speed, climb_speed, burrow_speed, darkvision
+		) VALUES
+		(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+	`, subrace.RaceSlug, subrace.Slug, subrace.Name, subrace.Description, subrace.DocumentSlug,
+		asi, subrace.AsiDescription, subrace.Traits, speed.Walk, speed.Swim,
+		speed.Fly, speed.Climb, speed.Burrow, orNull(subraceDarkvision(subrace)))
+	if err != nil {
+		return fmt.Errorf("failed to insert row into subrace_imports table: %w", err)
+	}
+	err = writeRaceAsiToDB(db, subrace.Slug, subrace.Asi, subrace.AsiDescription)
+	if err != nil {
+		return err
+	}
+	err = writeRaceTraitsToDB(db, subrace.Slug, subrace.Traits)
+	if err != nil {
+		return err
+	}
+	return nil
+}
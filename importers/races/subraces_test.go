@@ -0,0 +1,121 @@
+package races
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/open5e"
+)
+
+func TestRaceOptions(t *testing.T) {
+	path := "../../sql_database/subraces_test.db"
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	db, err := sqlx.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open sqlite db: %v", err)
+	}
+	defer db.Close()
+
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	races, _ := convertJsonToRaceImports(data, open5e.V1)
+	if err := writeRacesToDB(db, races); err != nil {
+		t.Fatal(err)
+	}
+
+	var subraces int
+	if err := db.Get(&subraces, `SELECT COUNT(*) FROM subrace_imports`); err != nil {
+		t.Fatal(err)
+	}
+	if subraces != 34 {
+		t.Errorf("%d subraces, want 34", subraces)
+	}
+
+	type option struct {
+		Slug       string `db:"slug"`
+		Name       string `db:"name"`
+		RaceSlug   string `db:"race_slug"`
+		Asi        string `db:"asi"`
+		WalkSpeed  int32  `db:"walk_speed"`
+		SwimSpeed  int32  `db:"swim_speed"`
+		Darkvision int32  `db:"darkvision"`
+	}
+	tests := []option{
+		{"hill-dwarf", "Hill Dwarf", "dwarf", `[{"attributes":["Constitution"],"value":2},{"attributes":["Wisdom"],"value":1}]`, 25, 0, 60},
+		// the subrace's swimming speed is added to the race's walking speed
+		{"bhain-kwai", "Bhain Kwai", "minotaur", "", 30, 25, 60},
+		// races without subraces are options of their own
+		{"dragonborn", "Dragonborn", "dragonborn", "", 30, 0, 0},
+	}
+	for _, test := range tests {
+		var got option
+		err := db.Get(&got, `
+			SELECT slug, name, race_slug, asi, COALESCE(walk_speed, 0) AS walk_speed,
+				COALESCE(swim_speed, 0) AS swim_speed, COALESCE(darkvision, 0) AS darkvision
+			FROM race_options WHERE slug = ?
+		`, test.Slug)
+		if err != nil {
+			t.Errorf("%s: %v", test.Slug, err)
+			continue
+		}
+		if test.Asi == "" {
+			test.Asi = got.Asi
+		}
+		if got != test {
+			t.Errorf("%s: got %+v, want %+v", test.Slug, got, test)
+		}
+	}
+
+	// races with subraces are only options through them
+	var dwarf int
+	if err := db.Get(&dwarf, `SELECT COUNT(*) FROM race_options WHERE slug = 'dwarf'`); err != nil {
+		t.Fatal(err)
+	}
+	if dwarf != 0 {
+		t.Errorf("dwarf is an option of its own")
+	}
+
+	var traits string
+	if err := db.Get(&traits, `SELECT traits FROM race_options WHERE slug = 'hill-dwarf'`); err != nil {
+		t.Fatal(err)
+	}
+	for _, trait := range []string{"Dwarven Resilience", "Dwarven Toughness"} {
+		if !strings.Contains(traits, trait) {
+			t.Errorf("hill-dwarf traits missing %q", trait)
+		}
+	}
+}
+
+func TestSubraceStats(t *testing.T) {
+	tests := []struct {
+		traits     string
+		walk       int32
+		darkvision int32
+	}{
+		// a wood elf's speed increases, and its other traits' ranges are
+		// not darkvision
+		{"**_Fleet of Foot._** Your base walking speed increases to 35 feet.\n\n" +
+			"**_Keen Hearing._** You can hear a whisper within 30 feet of you.", 35, 0},
+		{"***Superior Darkvision.*** Accustomed to life underground, you can see in dim light within 120 feet of you as if it were bright light.", 0, 120},
+	}
+	for _, test := range tests {
+		subrace := Subrace{Slug: "test", Traits: test.traits}
+		walk := int32(0)
+		if speed := subraceSpeed(subrace); speed.Walk != nil {
+			walk = *speed.Walk
+		}
+		if walk != test.walk {
+			t.Errorf("walking speed %d, want %d in %q", walk, test.walk, test.traits)
+		}
+		if darkvision := subraceDarkvision(subrace); darkvision != test.darkvision {
+			t.Errorf("darkvision %d, want %d in %q", darkvision, test.darkvision, test.traits)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package races
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// RaceTrait is a paragraph of a race's traits, e.g. "**_Dwarven
+// Resilience._** You have advantage on saving throws against poison, ...".
+type RaceTrait struct {
+	Name string
+	Body string
+}
+
+// RaceFacts are the numbers in a race's description an NPC can be made
+// from.  heights are in feet, and a fact the race doesn't give is 0.
+type RaceFacts struct {
+	Darkvision    int32
+	MaturityAge   int32
+	Lifespan      int32
+	MinHeight     int32
+	MaxHeight     int32
+	AverageWeight int32
+}
+
+var (
+	// "**_Name._**", "***Name.***", or "**Name**" on a line of its own
+	traitHeading  = regexp.MustCompile(`(?m)^[ \t]*(?:\*\*_(.+?)_\*\*|\*\*\*(.+?)\*\*\*|\*\*([^*_]+?)\*\*)[ \t]*`)
+	maturityRe    = regexp.MustCompile(`(?i)\b(?:adult\w*|maturity|young until)[^.]*?\b(?:age of|age|at|by|around) (\d+)\b`)
+	lifespanRe    = regexp.MustCompile(`(?i)\b(?:live|lives|survive|exceeding|fading away)\b([^.]*)`)
+	numberRe      = regexp.MustCompile(`\d+`)
+	heightRangeRe = regexp.MustCompile(`(?i)(\d+)(?: feet)? (?:and|to) (?:well over |over |almost )?(\d+) feet`)
+	heightMinRe   = regexp.MustCompile(`(?i)\bover (\d+) feet`)
+	heightMaxRe   = regexp.MustCompile(`(?i)\b(?:height of|up to|under) (\d+) feet`)
+	heightAverage = regexp.MustCompile(`(?i)\baverage (?:about )?(\d+) feet`)
+	weightRe      = regexp.MustCompile(`(?i)\b(?:average|averaging|weigh)\w* (?:about |around |almost )?(\d+) pounds`)
+)
+
+// ParseTraits splits a race's traits at their headings.  anything before
+// the first heading isn't a trait, and is dropped.  a heading repeated
+// straight after itself, like the dragonborn's "**Draconic Ancestry**"
+// table heading, is one trait.
+func ParseTraits(traits string) []RaceTrait {
+	var list []RaceTrait
+	headings := traitHeading.FindAllStringSubmatchIndex(traits, -1)
+	for i, h := range headings {
+		end := len(traits)
+		if i+1 < len(headings) {
+			end = headings[i+1][0]
+		}
+		var name string
+		for g := 2; g < len(h); g += 2 {
+			if h[g] >= 0 {
+				name = traits[h[g]:h[g+1]]
+			}
+		}
+		trait := RaceTrait{
+			Name: strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(name), ".")),
+			Body: strings.TrimSpace(traits[h[1]:end]),
+		}
+		if last := len(list) - 1; last >= 0 && list[last].Name == trait.Name {
+			list[last].Body += "\n\n" + trait.Body
+			continue
+		}
+		list = append(list, trait)
+	}
+	return list
+}
+
+// stripLabel removes the heading from a paragraph such as the race's age,
+// "**_Age._** Dwarves mature at ...".
+func stripLabel(text string) string {
+	if h := traitHeading.FindStringIndex(text); h != nil && strings.TrimSpace(text[:h[0]]) == "" {
+		return strings.TrimSpace(text[h[1]:])
+	}
+	return strings.TrimSpace(text)
+}
+
+// raceFacts reads the race's age, size and vision paragraphs.  "around the
+// age of 50" is a maturity age, "live to be around 350" a lifespan, and
+// where a race gives a span of years to live the longest is taken.
+// "between 4 and 5 feet tall" is a height range, and a race which only
+// gives an average height has it as both ends of the range.
+func raceFacts(race RaceImport) RaceFacts {
+	facts := RaceFacts{Darkvision: raceDarkvision(race)}
+
+	age := stripLabel(race.Age)
+	if m := maturityRe.FindStringSubmatch(age); m != nil {
+		facts.MaturityAge = atoi32(m[1])
+	}
+	for _, m := range lifespanRe.FindAllStringSubmatch(age, -1) {
+		for _, number := range numberRe.FindAllString(m[1], -1) {
+			if n := atoi32(number); n > facts.Lifespan {
+				facts.Lifespan = n
+			}
+		}
+	}
+
+	size := stripLabel(race.Size)
+	if m := heightRangeRe.FindStringSubmatch(size); m != nil {
+		facts.MinHeight, facts.MaxHeight = atoi32(m[1]), atoi32(m[2])
+	} else if m := heightAverage.FindStringSubmatch(size); m != nil {
+		facts.MinHeight = atoi32(m[1])
+		facts.MaxHeight = facts.MinHeight
+	} else {
+		if m := heightMinRe.FindStringSubmatch(size); m != nil {
+			facts.MinHeight = atoi32(m[1])
+		}
+		if m := heightMaxRe.FindStringSubmatch(size); m != nil {
+			facts.MaxHeight = atoi32(m[1])
+		}
+	}
+	if m := weightRe.FindStringSubmatch(size); m != nil {
+		facts.AverageWeight = atoi32(m[1])
+	}
+	return facts
+}
+
+func createRaceTraitsTable(db *sqlx.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS race_traits (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			race_slug TEXT,
+			position INTEGER,
+			name TEXT,
+			body TEXT
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create race_traits table: %w", err)
+	}
+	return nil
+}
+
+// writes a row to race_traits for each of the traits.  subraces are
+// written under their own slug.
+func writeRaceTraitsToDB(db sqlx.Execer, slug string, traits string) error {
+	for i, trait := range ParseTraits(traits) {
+		_, err := db.Exec(`INSERT INTO race_traits (race_slug, position, name, body) VALUES (?, ?, ?, ?);`,
+			slug, i, trait.Name, trait.Body)
+		if err != nil {
+			return fmt.Errorf("failed to insert row into race_traits table: %w", err)
+		}
+	}
+	return nil
+}
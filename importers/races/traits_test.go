@@ -0,0 +1,101 @@
+package races
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"open5e_importer/open5e"
+)
+
+func TestParseTraits(t *testing.T) {
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	races, _ := convertJsonToRaceImports(data, open5e.V1)
+	traits := map[string][]RaceTrait{}
+	for _, race := range races {
+		traits[race.Slug] = ParseTraits(race.Traits)
+	}
+
+	var names []string
+	for _, trait := range traits["dwarf"] {
+		names = append(names, trait.Name)
+	}
+	if got, want := strings.Join(names, ", "), "Dwarven Resilience, Dwarven Combat Training, Tool Proficiency, Stonecunning"; got != want {
+		t.Errorf("dwarf traits %q, want %q", got, want)
+	}
+	if body := traits["dwarf"][0].Body; body != "You have advantage on saving throws against poison, and you have resistance against poison damage." {
+		t.Errorf("unexpected Dwarven Resilience %q", body)
+	}
+
+	// "***Name.***" headings
+	if len(traits["derro"]) != 2 || traits["derro"][1].Name != "Sunlight Sensitivity" {
+		t.Errorf("unexpected derro traits %+v", traits["derro"])
+	}
+
+	// the table goes with the trait it's the heading of
+	dragonborn := traits["dragonborn"]
+	if len(dragonborn) != 3 || dragonborn[0].Name != "Draconic Ancestry" ||
+		!strings.HasPrefix(dragonborn[0].Body, "| Dragon") || !strings.Contains(dragonborn[0].Body, "You have draconic ancestry.") {
+		t.Errorf("unexpected dragonborn traits %+v", dragonborn)
+	}
+
+	if len(traits["human"]) != 0 {
+		t.Errorf("human has traits %+v", traits["human"])
+	}
+}
+
+func TestRaceFacts(t *testing.T) {
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	races, _ := convertJsonToRaceImports(data, open5e.V1)
+	facts := map[string]RaceFacts{}
+	for _, race := range races {
+		facts[race.Slug] = raceFacts(race)
+	}
+
+	tests := []struct {
+		slug  string
+		facts RaceFacts
+	}{
+		{"dwarf", RaceFacts{Darkvision: 60, MaturityAge: 50, Lifespan: 350, MinHeight: 4, MaxHeight: 5, AverageWeight: 150}},
+		// "claims adulthood and an adult name around the age of 100"
+		{"elf", RaceFacts{Darkvision: 60, MaturityAge: 100, Lifespan: 750, MinHeight: 5, MaxHeight: 6}},
+		// "lives into the middle of his or her second century", and only an
+		// average height
+		{"halfling", RaceFacts{MaturityAge: 20, MinHeight: 3, MaxHeight: 3, AverageWeight: 40}},
+		// "live 350 to almost 500 years"
+		{"gnome", RaceFacts{Darkvision: 60, MaturityAge: 40, Lifespan: 500, MinHeight: 3, MaxHeight: 4, AverageWeight: 40}},
+		// "standing well over 6 feet tall"
+		{"dragonborn", RaceFacts{MaturityAge: 15, Lifespan: 80, MinHeight: 6, AverageWeight: 250}},
+		{"derro", RaceFacts{Darkvision: 120, MaturityAge: 15, Lifespan: 75, MinHeight: 3, MaxHeight: 4}},
+		// "Adult males can reach a height of 7 feet"
+		{"minotaur", RaceFacts{Darkvision: 60, MaturityAge: 15, MaxHeight: 7}},
+	}
+	for _, test := range tests {
+		if got := facts[test.slug]; got != test.facts {
+			t.Errorf("%s: got %+v, want %+v", test.slug, got, test.facts)
+		}
+	}
+}
+
+func TestStripLabel(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"**_Age._** Dwarves mature at the same rate as humans.", "Dwarves mature at the same rate as humans."},
+		{"***Size.*** Your size is Medium.", "Your size is Medium."},
+		{"", ""},
+		{"Your size is Medium.", "Your size is Medium."},
+	}
+	for _, test := range tests {
+		if got := stripLabel(test.text); got != test.want {
+			t.Errorf("stripLabel(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
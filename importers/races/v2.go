@@ -0,0 +1,77 @@
+package races
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"open5e_importer/open5e"
+)
+
+// the v2 API serves races as species, with every trait, including the
+// age, size and speed paragraphs v1 keeps in their own fields, in one
+// typed list.  subspecies are served from the same endpoint, and are
+// imported as subraces of the race they're a subspecies of.
+type speciesV2 struct {
+	Key          string           `json:"key"`
+	Name         string           `json:"name"`
+	Desc         string           `json:"desc"`
+	Document     open5e.Named     `json:"document"`
+	IsSubspecies bool             `json:"is_subspecies"`
+	SubspeciesOf *open5e.Named    `json:"subspecies_of"`
+	Traits       []speciesTraitV2 `json:"traits"`
+}
+
+type speciesTraitV2 struct {
+	Name string `json:"name"`
+	Desc string `json:"desc"`
+	Type string `json:"type"`
+}
+
+func decodeRaceV2(raw json.RawMessage) (RaceImport, error) {
+	var species speciesV2
+	err := json.Unmarshal(raw, &species)
+	if err != nil {
+		return RaceImport{}, err
+	}
+
+	race := RaceImport{
+		Name:         species.Name,
+		Slug:         species.Key,
+		Description:  species.Desc,
+		DocumentSlug: species.Document.Key,
+	}
+	if species.SubspeciesOf != nil {
+		race.SubraceOf = species.SubspeciesOf.Key
+	}
+
+	// traits are rendered in the "**_Name._** text" style v1 uses
+	var traits []string
+	for _, trait := range species.Traits {
+		text := fmt.Sprintf("**_%s._** %s", trait.Name, trait.Desc)
+		switch {
+		case trait.Type == "ABILITY_SCORE":
+			race.AsiDescription = text
+		case race.SubraceOf != "":
+			// a subrace keeps all its other traits together
+			traits = append(traits, text)
+		case trait.Type == "AGE":
+			race.Age = text
+		case trait.Type == "ALIGNMENT":
+			race.Alignment = text
+		case trait.Type == "SIZE":
+			race.Size = text
+		case trait.Type == "SPEED":
+			race.SpeedDescription = text
+		case trait.Type == "LANGUAGES":
+			race.Languages = text
+		case trait.Type == "VISION":
+			race.Vision = text
+		default:
+			traits = append(traits, text)
+		}
+	}
+	race.Traits = strings.Join(traits, "\n\n")
+
+	return race, nil
+}
@@ -0,0 +1,109 @@
+package sections
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"open5e_importer/open5e"
+)
+
+// Import fetches every section from the Open5e API and writes them to db,
+// returning the number of sections imported.
+func Import(db *sqlx.DB, client *open5e.Client, version open5e.Version) (int, error) {
+	return open5e.Import(client, sectionResource, version, func(page []SectionImport) error {
+		return writeSectionsToDB(db, page)
+	})
+}
+
+type SectionImport struct {
+	Name         string `json:"name" db:"name"`
+	Slug         string `json:"slug" db:"slug"`
+	Description  string `json:"desc" db:"description"`
+	Parent       string `json:"parent" db:"parent"`
+	ParentSlug   string `json:"-" db:"parent_slug"`
+	DocumentSlug string `json:"document__slug" db:"document_slug"`
+}
+
+// json keys of v1 results whose SectionImport field isn't named after them
+var sectionAliases = map[string]string{
+	"desc": "Description",
+}
+
+var sectionResource = open5e.Resource[SectionImport]{
+	V1Path:   "sections",
+	DecodeV1: decodeSectionV1,
+	V2Path:   "rules",
+	DecodeV2: decodeSectionV2,
+}
+
+// v1 gives a section's parent by name, so its slug is made from the name
+// the way v1 makes slugs.
+func decodeSectionV1(raw json.RawMessage) (SectionImport, error) {
+	section, err := open5e.DecodeV1[SectionImport](sectionAliases)(raw)
+	if section.Parent != "" {
+		section.ParentSlug = open5e.Slugify(section.Parent)
+	}
+	return section, err
+}
+
+// writes the sections to section_imports, replacing the row of a section
+// imported before by its slug.
+func writeSectionsToDB(db *sqlx.DB, sections []SectionImport) error {
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS section_imports (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT,
+			slug TEXT UNIQUE,
+			description TEXT,
+			parent TEXT,
+			parent_slug TEXT,
+			document_slug TEXT REFERENCES documents(slug)
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create section_imports: %w", err)
+	}
+
+	for idx := range sections {
+		query := `
+			INSERT INTO section_imports (
+				name, slug, description, parent, parent_slug,
+				document_slug
+			)
+			VALUES
+			(?, ?, ?, ?, ?, ?)
+			ON CONFLICT(slug) DO UPDATE SET
+				name = excluded.name,
+				description = excluded.description,
+				parent = excluded.parent,
+				parent_slug = excluded.parent_slug,
+				document_slug = excluded.document_slug
+		`
+		section := sections[idx]
+		var parentSlug interface{}
+		if section.ParentSlug != "" {
+			parentSlug = section.ParentSlug
+		}
+		_, err = db.Exec(query, section.Name, section.Slug, section.Description, section.Parent, parentSlug,
+			section.DocumentSlug)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func convertJsonToSectionImports(jsonData []byte, version open5e.Version) ([]SectionImport, string) {
+	sections, nextUrl, err := open5e.DecodePage(sectionResource, version, jsonData)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if nextUrl == "" {
+		return sections, "DONE"
+	}
+	return sections, nextUrl
+}
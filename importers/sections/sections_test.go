@@ -0,0 +1,79 @@
+package sections
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/open5e"
+)
+
+func TestImportSections(t *testing.T) {
+	err := os.Remove("../../sql_database/sections_test.db")
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	db, err := sqlx.Open("sqlite3", "../../sql_database/sections_test.db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sections, _ := convertJsonToSectionImports(data, open5e.V1)
+	// importing twice replaces the rows rather than adding to them
+	for i := 0; i < 2; i++ {
+		if err := writeSectionsToDB(db, sections); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// sections are grouped by their parent's slug
+	var groups []string
+	err = db.Select(&groups, `
+		SELECT parent_slug || ' ' || GROUP_CONCAT(slug, ',') FROM (
+			SELECT parent_slug, slug FROM section_imports ORDER BY id
+		) GROUP BY parent_slug ORDER BY parent_slug
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"combat actions-in-combat,making-an-attack,damage-and-healing",
+		"equipment adventuring-gear",
+		"gameplay-mechanics resting,abilities",
+		"spellcasting spellcasting",
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("sections by parent = %q, want %q", groups, want)
+	}
+}
+
+func TestDecodeSectionsV2(t *testing.T) {
+	v1Data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2Data, err := ioutil.ReadFile("./test_data/testdata_v2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1Sections, _ := convertJsonToSectionImports(v1Data, open5e.V1)
+	v2Sections, _ := convertJsonToSectionImports(v2Data, open5e.V2)
+	if len(v2Sections) != 4 {
+		t.Fatalf("expected 4 sections, got %d", len(v2Sections))
+	}
+	// v2 parents are rulesets, whose keys carry their document
+	for i, v2 := range v2Sections {
+		v1 := v1Sections[i]
+		if v2.Slug != "srd_"+v1.Slug || v2.Name != v1.Name || v2.Description != v1.Description ||
+			v2.Parent != v1.Parent || v2.ParentSlug != "srd_"+v1.ParentSlug {
+			t.Errorf("unexpected section %+v, expected %+v", v2, v1)
+		}
+	}
+}
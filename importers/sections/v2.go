@@ -0,0 +1,34 @@
+package sections
+
+import (
+	"encoding/json"
+
+	"open5e_importer/open5e"
+)
+
+// the v2 API serves rules sections as rules grouped into rulesets, so
+// the ruleset takes the place of the v1 parent section.
+type ruleV2 struct {
+	Key      string       `json:"key"`
+	Name     string       `json:"name"`
+	Desc     string       `json:"desc"`
+	Document open5e.Named `json:"document"`
+	Ruleset  open5e.Named `json:"ruleset"`
+}
+
+func decodeSectionV2(raw json.RawMessage) (SectionImport, error) {
+	var rule ruleV2
+	err := json.Unmarshal(raw, &rule)
+	if err != nil {
+		return SectionImport{}, err
+	}
+
+	return SectionImport{
+		Name:         rule.Name,
+		Slug:         rule.Key,
+		Description:  rule.Desc,
+		Parent:       rule.Ruleset.Name,
+		ParentSlug:   rule.Ruleset.Key,
+		DocumentSlug: rule.Document.Key,
+	}, nil
+}
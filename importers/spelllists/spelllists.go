@@ -0,0 +1,170 @@
+package spelllists
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"open5e_importer/open5e"
+)
+
+// Import fetches every spell list from the Open5e API and writes them to db,
+// returning the number of spell lists imported.
+func Import(db *sqlx.DB, client *open5e.Client, version open5e.Version) (int, error) {
+	return open5e.Import(client, spellListResource, version, func(page []SpellListImport) error {
+		return writeSpellListsToDB(db, page)
+	})
+}
+
+type SpellListImport struct {
+	Name         string   `json:"name"`
+	Slug         string   `json:"slug"`
+	Description  string   `json:"desc"`
+	Spells       []string `json:"spells"`
+	DocumentSlug string   `json:"document__slug"`
+	// v2 has no spell lists, but gives each spell the classes it's on, so
+	// a v2 entry is a single spell with the class lists it belongs to.
+	SpellSlug string   `json:"-"`
+	Classes   []string `json:"-"`
+}
+
+// json keys of v1 results whose SpellListImport field isn't named after them
+var spellListAliases = map[string]string{
+	"desc": "Description",
+}
+
+var spellListResource = open5e.Resource[SpellListImport]{
+	V1Path:   "spelllist",
+	V2Path:   "spells",
+	DecodeV1: open5e.DecodeV1[SpellListImport](spellListAliases),
+	DecodeV2: decodeSpellListV2,
+}
+
+// the parts of a v2 spell the class lists are built from
+type spellV2 struct {
+	Key      string         `json:"key"`
+	Name     string         `json:"name"`
+	Classes  []open5e.Named `json:"classes"`
+	Document open5e.Named   `json:"document"`
+}
+
+func decodeSpellListV2(raw json.RawMessage) (SpellListImport, error) {
+	var v2 spellV2
+	err := json.Unmarshal(raw, &v2)
+	if err != nil {
+		return SpellListImport{}, err
+	}
+	spellList := SpellListImport{
+		Name:         v2.Name,
+		SpellSlug:    v2.Key,
+		DocumentSlug: v2.Document.Key,
+	}
+	for _, class := range v2.Classes {
+		spellList.Classes = append(spellList.Classes, class.Key)
+	}
+	return spellList, nil
+}
+
+type ClassSpell struct {
+	ClassSlug  string `db:"class_slug"`
+	SpellSlug  string `db:"spell_slug"`
+	Name       string `db:"name"`
+	SpellLevel int32  `db:"spell_level"`
+}
+
+// each spell list is keyed by the slug of the class that uses it, so
+// its spells are written straight into the class_spells join table.  a
+// v2 entry is one spell, which is written under each of its classes.
+func writeSpellListsToDB(db *sqlx.DB, spellLists []SpellListImport) error {
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS class_spells (
+			class_slug TEXT NOT NULL,
+			spell_slug TEXT NOT NULL,
+			document_slug TEXT REFERENCES documents(slug),
+			PRIMARY KEY (class_slug, spell_slug)
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create class_spells: %w", err)
+	}
+
+	for _, spellList := range spellLists {
+		tx, err := db.Beginx()
+		if err != nil {
+			return err
+		}
+		err = writeSpellListToDB(tx, spellList)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// a class's list is replaced as a whole, so spells which have left it
+// upstream don't stay behind.  likewise a v2 spell's classes.
+func writeSpellListToDB(tx *sqlx.Tx, spellList SpellListImport) error {
+	var err error
+	rows := [][2]string{}
+	if spellList.SpellSlug != "" {
+		_, err = tx.Exec(`DELETE FROM class_spells WHERE spell_slug = ?`, spellList.SpellSlug)
+		for _, classSlug := range spellList.Classes {
+			rows = append(rows, [2]string{classSlug, spellList.SpellSlug})
+		}
+	} else {
+		_, err = tx.Exec(`DELETE FROM class_spells WHERE class_slug = ?`, spellList.Slug)
+		for _, spellSlug := range spellList.Spells {
+			rows = append(rows, [2]string{spellList.Slug, spellSlug})
+		}
+	}
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		_, err = tx.Exec(`
+			INSERT OR IGNORE INTO class_spells (class_slug, spell_slug, document_slug)
+			VALUES
+			(?, ?, ?)
+		`, row[0], row[1], spellList.DocumentSlug)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SpellsForClassAtLevel returns the spells on a class's spell list at the
+// given spell level, using 0 for cantrips, ordered by name.  classSlug is
+// the class's slug in class_imports.  spells which haven't been imported
+// into spell_imports yet are left out.
+func SpellsForClassAtLevel(db *sqlx.DB, classSlug string, level int32) ([]ClassSpell, error) {
+	spells := []ClassSpell{}
+	err := db.Select(&spells, `
+		SELECT class_spells.class_slug, class_spells.spell_slug,
+			spell_imports.name, spell_imports.level_int AS spell_level
+		FROM class_spells
+		JOIN spell_imports ON spell_imports.slug = class_spells.spell_slug
+		WHERE class_spells.class_slug = ? AND spell_imports.level_int = ?
+		ORDER BY spell_imports.name
+	`, classSlug, level)
+	return spells, err
+}
+
+func convertJsonToSpellListImports(jsonData []byte, version open5e.Version) ([]SpellListImport, string) {
+	spellLists, nextUrl, err := open5e.DecodePage(spellListResource, version, jsonData)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if nextUrl == "" {
+		return spellLists, "DONE"
+	}
+	return spellLists, nextUrl
+}
@@ -0,0 +1,140 @@
+package spelllists
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/open5e"
+)
+
+func TestImportSpellLists(t *testing.T) {
+	err := os.Remove("../../sql_database/spelllists_test.db")
+	if err != nil {
+		if os.IsNotExist(err) {
+			// don't worry about it!
+		} else {
+			log.Fatal(err)
+		}
+	}
+	db, err := sqlx.Open("sqlite3", "../../sql_database/spelllists_test.db")
+	if err != nil {
+		log.Fatalf("Failed to open sqlite db: %v", err)
+	}
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spellLists, _ := convertJsonToSpellListImports(data, open5e.V1)
+	if err := writeSpellListsToDB(db, spellLists); err != nil {
+		t.Fatal(err)
+	}
+
+	// spell_imports is owned by the spells importer, so only the columns
+	// SpellsForClassAtLevel reads are created here.
+	_, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS spell_imports (name TEXT, slug TEXT, level_int INTEGER);
+		DELETE FROM spell_imports;
+		INSERT INTO spell_imports (name, slug, level_int) VALUES
+			('Vicious Mockery', 'vicious-mockery', 0),
+			('Charm Person', 'charm-person', 1),
+			('Healing Word', 'healing-word', 1),
+			('Magic Missile', 'magic-missile', 1),
+			('Fireball', 'fireball', 3);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spells, err := SpellsForClassAtLevel(db, "bard", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spells) != 2 || spells[0].SpellSlug != "charm-person" || spells[1].SpellSlug != "healing-word" {
+		t.Errorf("expected charm-person and healing-word for a 1st level bard, got %v", spells)
+	}
+
+	spells, err = SpellsForClassAtLevel(db, "wizard", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spells) != 1 || spells[0].Name != "Fireball" {
+		t.Errorf("expected fireball for a 3rd level wizard, got %v", spells)
+	}
+
+	// a re-import replaces each class's list, dropping spells which have
+	// left it
+	for i := range spellLists {
+		if spellLists[i].Slug == "wizard" {
+			spellLists[i].Spells = []string{"magic-missile"}
+		}
+	}
+	if err := writeSpellListsToDB(db, spellLists); err != nil {
+		t.Fatal(err)
+	}
+	spells, err = SpellsForClassAtLevel(db, "wizard", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spells) != 0 {
+		t.Errorf("expected no 3rd level wizard spells after fireball was dropped, got %v", spells)
+	}
+	var count int
+	if err := db.Get(&count, `SELECT COUNT(*) FROM class_spells`); err != nil {
+		t.Fatal(err)
+	}
+	if count != 17 {
+		t.Errorf("%d class_spells after a re-import, want 17", count)
+	}
+}
+
+func TestImportSpellListsV2(t *testing.T) {
+	err := os.Remove("../../sql_database/spelllists_v2_test.db")
+	if err != nil && !os.IsNotExist(err) {
+		log.Fatal(err)
+	}
+	db, err := sqlx.Open("sqlite3", "../../sql_database/spelllists_v2_test.db")
+	if err != nil {
+		log.Fatalf("Failed to open sqlite db: %v", err)
+	}
+	data, err := ioutil.ReadFile("./test_data/testdata_v2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// v2 has no spell lists, so each entry is a spell and its classes
+	spellLists, _ := convertJsonToSpellListImports(data, open5e.V2)
+	if len(spellLists) != 8 {
+		t.Fatalf("decoded %d spells, want 8", len(spellLists))
+	}
+	if err := writeSpellListsToDB(db, spellLists); err != nil {
+		t.Fatal(err)
+	}
+
+	var bard []string
+	err = db.Select(&bard, `SELECT spell_slug FROM class_spells WHERE class_slug = 'srd_bard' ORDER BY spell_slug`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bard) != 5 || bard[0] != "srd_charm-person" || bard[4] != "srd_vicious-mockery" {
+		t.Errorf("unexpected srd_bard spells %v", bard)
+	}
+
+	// a re-import replaces each spell's classes
+	for i := range spellLists {
+		if spellLists[i].SpellSlug == "srd_fireball" {
+			spellLists[i].Classes = []string{"srd_wizard"}
+		}
+	}
+	if err := writeSpellListsToDB(db, spellLists); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	if err := db.Get(&count, `SELECT COUNT(*) FROM class_spells`); err != nil {
+		t.Fatal(err)
+	}
+	if count != 21 {
+		t.Errorf("%d class_spells after a re-import, want 21", count)
+	}
+}
@@ -0,0 +1,141 @@
+package spells
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"open5e_importer/open5e"
+)
+
+// Import fetches every spell from the Open5e API and writes them to db,
+// returning the number of spells imported.
+func Import(db *sqlx.DB, client *open5e.Client, version open5e.Version) (int, error) {
+	return open5e.Import(client, spellResource, version, func(page []SpellImport) error {
+		return writeSpellsToDB(db, page)
+	})
+}
+
+type SpellImport struct {
+	Name                       string   `json:"name" db:"name"`
+	Slug                       string   `json:"slug" db:"slug"`
+	Description                string   `json:"desc" db:"description"`
+	HigherLevel                string   `json:"higher_level" db:"higher_level"`
+	Page                       string   `json:"page" db:"page"`
+	Range                      string   `json:"range" db:"range"`
+	TargetRangeSort            int32    `json:"target_range_sort" db:"target_range_sort"`
+	Components                 string   `json:"components" db:"components"`
+	RequiresVerbalComponents   bool     `json:"requires_verbal_components" db:"requires_verbal_components"`
+	RequiresSomaticComponents  bool     `json:"requires_somatic_components" db:"requires_somatic_components"`
+	RequiresMaterialComponents bool     `json:"requires_material_components" db:"requires_material_components"`
+	Material                   string   `json:"material" db:"material"`
+	CanBeCastAsRitual          bool     `json:"can_be_cast_as_ritual" db:"can_be_cast_as_ritual"`
+	Ritual                     string   `json:"ritual" db:"ritual"`
+	Duration                   string   `json:"duration" db:"duration"`
+	Concentration              string   `json:"concentration" db:"concentration"`
+	RequiresConcentration      bool     `json:"requires_concentration" db:"requires_concentration"`
+	CastingTime                string   `json:"casting_time" db:"casting_time"`
+	Level                      string   `json:"level" db:"level"`
+	LevelInt                   int32    `json:"level_int" db:"level_int"`
+	SpellLevel                 int32    `json:"spell_level" db:"spell_level"`
+	School                     string   `json:"school" db:"school"`
+	DndClass                   string   `json:"dnd_class" db:"dnd_class"`
+	SpellLists                 []string `json:"spell_lists" db:"spell_lists"`
+	Archetype                  string   `json:"archetype" db:"archetype"`
+	Circles                    string   `json:"circles" db:"circles"`
+	DocumentSlug               string   `json:"document__slug" db:"document_slug"`
+}
+
+// json keys of v1 results whose SpellImport field isn't named after them
+var spellAliases = map[string]string{
+	"desc": "Description",
+}
+
+var spellResource = open5e.Resource[SpellImport]{
+	V1Path:   "spells",
+	DecodeV1: open5e.DecodeV1[SpellImport](spellAliases),
+	V2Path:   "spells",
+	DecodeV2: decodeSpellV2,
+}
+
+func writeSpellsToDB(db *sqlx.DB, spells []SpellImport) error {
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS spell_imports (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT,
+			slug TEXT,
+			description TEXT,
+			higher_level TEXT,
+			page TEXT,
+			range TEXT,
+			target_range_sort INTEGER,
+			components TEXT,
+			requires_verbal_components BOOLEAN,
+			requires_somatic_components BOOLEAN,
+			requires_material_components BOOLEAN,
+			material TEXT,
+			can_be_cast_as_ritual BOOLEAN,
+			ritual TEXT,
+			duration TEXT,
+			concentration TEXT,
+			requires_concentration BOOLEAN,
+			casting_time TEXT,
+			level TEXT,
+			level_int INTEGER,
+			school TEXT,
+			dnd_class TEXT,
+			spell_lists TEXT,
+			archetype TEXT,
+			circles TEXT,
+			document_slug TEXT REFERENCES documents(slug)
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create spell_imports: %w", err)
+	}
+
+	for idx := range spells {
+		query := `
+			INSERT INTO spell_imports (
+				name, slug, description, higher_level, page, range, target_range_sort,
+				components, requires_verbal_components, requires_somatic_components,
+				requires_material_components, material, can_be_cast_as_ritual, ritual,
+				duration, concentration, requires_concentration, casting_time, level,
+				level_int, school, dnd_class, spell_lists, archetype, circles, document_slug
+			)
+			VALUES
+			(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`
+		spell := spells[idx]
+		spellListsJson, err := json.Marshal(spell.SpellLists)
+		if err != nil {
+			return err
+		}
+
+		_, err = db.Exec(query, spell.Name, spell.Slug, spell.Description, spell.HigherLevel,
+			spell.Page, spell.Range, spell.TargetRangeSort, spell.Components,
+			spell.RequiresVerbalComponents, spell.RequiresSomaticComponents,
+			spell.RequiresMaterialComponents, spell.Material, spell.CanBeCastAsRitual,
+			spell.Ritual, spell.Duration, spell.Concentration, spell.RequiresConcentration,
+			spell.CastingTime, spell.Level, spell.LevelInt, spell.School, spell.DndClass,
+			spellListsJson, spell.Archetype, spell.Circles, spell.DocumentSlug)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func convertJsonToSpellImports(jsonData []byte, version open5e.Version) ([]SpellImport, string) {
+	spells, nextUrl, err := open5e.DecodePage(spellResource, version, jsonData)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if nextUrl == "" {
+		return spells, "DONE"
+	}
+	return spells, nextUrl
+}
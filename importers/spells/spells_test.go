@@ -0,0 +1,65 @@
+package spells
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"open5e_importer/open5e"
+)
+
+func TestImportSpells(t *testing.T) {
+	err := os.Remove("../../sql_database/spells_test.db")
+	if err != nil {
+		if os.IsNotExist(err) {
+			// don't worry about it!
+		} else {
+			log.Fatal(err)
+		}
+	}
+	db, err := sqlx.Open("sqlite3", "../../sql_database/spells_test.db")
+	if err != nil {
+		log.Fatalf("Failed to open sqlite db: %v", err)
+	}
+	data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spells, _ := convertJsonToSpellImports(data, open5e.V1)
+	if err := writeSpellsToDB(db, spells); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDecodeSpellsV2(t *testing.T) {
+	v1Data, err := ioutil.ReadFile("./test_data/testdata.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2Data, err := ioutil.ReadFile("./test_data/testdata_v2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1Spells, _ := convertJsonToSpellImports(v1Data, open5e.V1)
+	v2Spells, _ := convertJsonToSpellImports(v2Data, open5e.V2)
+	if len(v2Spells) != len(v1Spells) {
+		t.Fatalf("expected %d spells, got %d", len(v1Spells), len(v2Spells))
+	}
+	for i, v2 := range v2Spells {
+		v1 := v1Spells[i]
+		if v2.Slug != "srd_"+v1.Slug || v2.Name != v1.Name || v2.DocumentSlug != "srd" {
+			t.Errorf("unexpected spell %q, expected %q", v2.Slug, v1.Slug)
+		}
+		if v2.Level != v1.Level || v2.LevelInt != v1.LevelInt || v2.School != v1.School {
+			t.Errorf("%s: level or school differs between v1 and v2", v1.Slug)
+		}
+		if v2.Components != v1.Components || v2.Concentration != v1.Concentration || v2.DndClass != v1.DndClass {
+			t.Errorf("%s: casting details differ between v1 and v2", v1.Slug)
+		}
+		if len(v2.SpellLists) != len(v1.SpellLists) || v2.SpellLists[0] != "srd_"+v1.SpellLists[0] {
+			t.Errorf("%s: unexpected spell lists %v", v1.Slug, v2.SpellLists)
+		}
+	}
+}
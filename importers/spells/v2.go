@@ -0,0 +1,107 @@
+package spells
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"open5e_importer/open5e"
+)
+
+// the v2 API gives spell components and concentration as booleans, the
+// level as a number and the classes as references.
+type spellV2 struct {
+	Key               string         `json:"key"`
+	Name              string         `json:"name"`
+	Desc              string         `json:"desc"`
+	HigherLevel       string         `json:"higher_level"`
+	Level             int32          `json:"level"`
+	School            open5e.Named   `json:"school"`
+	Classes           []open5e.Named `json:"classes"`
+	CastingTime       string         `json:"casting_time"`
+	RangeText         string         `json:"range_text"`
+	Range             float32        `json:"range"`
+	Verbal            bool           `json:"verbal"`
+	Somatic           bool           `json:"somatic"`
+	Material          bool           `json:"material"`
+	MaterialSpecified string         `json:"material_specified"`
+	Ritual            bool           `json:"ritual"`
+	Duration          string         `json:"duration"`
+	Concentration     bool           `json:"concentration"`
+	Document          open5e.Named   `json:"document"`
+}
+
+func decodeSpellV2(raw json.RawMessage) (SpellImport, error) {
+	var v2 spellV2
+	err := json.Unmarshal(raw, &v2)
+	if err != nil {
+		return SpellImport{}, err
+	}
+
+	spell := SpellImport{
+		Name:                       v2.Name,
+		Slug:                       v2.Key,
+		Description:                v2.Desc,
+		HigherLevel:                v2.HigherLevel,
+		Range:                      v2.RangeText,
+		TargetRangeSort:            int32(v2.Range),
+		RequiresVerbalComponents:   v2.Verbal,
+		RequiresSomaticComponents:  v2.Somatic,
+		RequiresMaterialComponents: v2.Material,
+		Material:                   v2.MaterialSpecified,
+		CanBeCastAsRitual:          v2.Ritual,
+		Ritual:                     yesNo(v2.Ritual),
+		Duration:                   v2.Duration,
+		Concentration:              yesNo(v2.Concentration),
+		RequiresConcentration:      v2.Concentration,
+		CastingTime:                v2.CastingTime,
+		Level:                      spellLevelName(v2.Level),
+		LevelInt:                   v2.Level,
+		SpellLevel:                 v2.Level,
+		School:                     strings.ToLower(v2.School.Name),
+		DocumentSlug:               v2.Document.Key,
+	}
+
+	var components []string
+	for _, component := range []struct {
+		required bool
+		letter   string
+	}{{v2.Verbal, "V"}, {v2.Somatic, "S"}, {v2.Material, "M"}} {
+		if component.required {
+			components = append(components, component.letter)
+		}
+	}
+	spell.Components = strings.Join(components, ", ")
+
+	var classes []string
+	for _, class := range v2.Classes {
+		classes = append(classes, class.Name)
+		spell.SpellLists = append(spell.SpellLists, class.Key)
+	}
+	spell.DndClass = strings.Join(classes, ", ")
+
+	return spell, nil
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// matches the v1 level names, "Cantrip", "1st-level", "2nd-level" and so on
+func spellLevelName(level int32) string {
+	switch level {
+	case 0:
+		return "Cantrip"
+	case 1:
+		return "1st-level"
+	case 2:
+		return "2nd-level"
+	case 3:
+		return "3rd-level"
+	default:
+		return fmt.Sprintf("%dth-level", level)
+	}
+}
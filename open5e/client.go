@@ -0,0 +1,92 @@
+package open5e
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Client fetches pages from the Open5e API.  A single client is shared by
+// every importer in a run, so its rate limit applies to the run as a whole.
+type Client struct {
+	http    *http.Client
+	limiter *time.Ticker
+}
+
+// NewClient returns a client making at most requestsPerSecond requests per
+// second, or an unlimited client if requestsPerSecond is not positive.
+func NewClient(requestsPerSecond float64) *Client {
+	client := &Client{http: &http.Client{Timeout: time.Minute}}
+	if requestsPerSecond > 0 {
+		client.limiter = time.NewTicker(time.Duration(float64(time.Second) / requestsPerSecond))
+	}
+	return client
+}
+
+// Close stops the client's rate limiter.  the client mustn't be used
+// afterwards.
+func (c *Client) Close() {
+	if c.limiter != nil {
+		c.limiter.Stop()
+	}
+}
+
+func (c *Client) Get(url string) ([]byte, error) {
+	if c.limiter != nil {
+		<-c.limiter.C
+	}
+
+	res, err := c.http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+
+	bodyBytes, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode > 299 {
+		return nil, fmt.Errorf("response failed with status code: %d and\nbody: %s", res.StatusCode, bodyBytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return bodyBytes, nil
+}
+
+// Import walks every page of a resource, handing each decoded page to
+// write, and returns the number of results written.  it stops at the
+// first page write fails on, returning its error.
+func Import[T any](client *Client, r Resource[T], v Version, write func([]T) error) (int, error) {
+	return importFrom(client, BaseUrl, r, v, write)
+}
+
+func importFrom[T any](client *Client, baseUrl string, r Resource[T], v Version, write func([]T) error) (int, error) {
+	nextUrl, err := r.urlFrom(baseUrl, v)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for nextUrl != "" {
+		body, err := client.Get(nextUrl)
+		if err != nil {
+			return count, err
+		}
+
+		items, next, err := DecodePage(r, v, body)
+		if err != nil {
+			return count, fmt.Errorf("%s: %w", nextUrl, err)
+		}
+		err = write(items)
+		if err != nil {
+			return count, fmt.Errorf("%s: %w", nextUrl, err)
+		}
+		count += len(items)
+
+		nextUrl = next
+		if nextUrl != "" {
+			fmt.Printf("next url to fetch: %s\n", nextUrl)
+		}
+	}
+	return count, nil
+}
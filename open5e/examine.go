@@ -0,0 +1,60 @@
+package open5e
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// DecodeV1 returns a decoder for v1 results, which have the shape of the
+// model T.  when importing, we want to examine each result for keys which
+// aren't fields on T, and decide if they should be added or not.  this is
+// a manual process, so they are only printed.  aliases maps the json keys
+// whose field isn't named after them, e.g. "desc" to "Description".
+func DecodeV1[T any](aliases map[string]string) func(json.RawMessage) (T, error) {
+	return func(raw json.RawMessage) (T, error) {
+		var item T
+		var result map[string]interface{}
+		err := json.Unmarshal(raw, &result)
+		if err != nil {
+			return item, err
+		}
+		for _, key := range UnknownKeys(reflect.TypeOf(item), result, aliases) {
+			fmt.Printf("Key/Value pair not found on %s:\nKey: %s, value: %v\n", reflect.TypeOf(item).Name(), key, result[key])
+		}
+		err = json.Unmarshal(raw, &item)
+		return item, err
+	}
+}
+
+// UnknownKeys lists the keys of result with no field on model.  keys are
+// matched to fields by camel casing them, "hit_dice" to "HitDice", unless
+// they are in aliases.  page_no and the document__ keys v1 adds to every
+// result are never reported.
+func UnknownKeys(model reflect.Type, result map[string]interface{}, aliases map[string]string) []string {
+	var unknown []string
+	for key := range result {
+		if key == "page_no" || strings.HasPrefix(key, "document__") {
+			continue
+		}
+		field, ok := aliases[key]
+		if !ok {
+			field = snakeToCamel(key)
+		}
+		if _, ok := model.FieldByName(field); !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+func snakeToCamel(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 0; i < len(parts); i++ {
+		parts[i] = strings.Title(parts[i])
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,131 @@
+// Package open5e describes the resources served by the Open5e public API
+// and decodes them, for either version of the API, into the importers'
+// own models.
+package open5e
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const BaseUrl = "https://api.open5e.com"
+
+type Version string
+
+const (
+	V1 Version = "v1"
+	V2 Version = "v2"
+)
+
+// ErrSkip is returned by a decoder for results which have no place in the
+// importer's model, e.g. v2 subclasses which are served alongside classes.
+var ErrSkip = errors.New("open5e: skip result")
+
+// ErrUnavailable is returned for a resource which has no equivalent in the
+// version of the API asked for, e.g. planes, which v2 doesn't serve.
+var ErrUnavailable = errors.New("open5e: resource is not available in this version of the API")
+
+func ParseVersion(s string) (Version, error) {
+	switch Version(s) {
+	case V1, V2:
+		return Version(s), nil
+	default:
+		return "", fmt.Errorf("unknown Open5e API version %q, expected %q or %q", s, V1, V2)
+	}
+}
+
+// Resource declares where a resource lives in each version of the API and
+// how to decode a single result into the model T.  A resource which has
+// no equivalent in one of the versions leaves that path and decoder empty,
+// and importing it from that version fails with ErrUnavailable.
+type Resource[T any] struct {
+	V1Path   string
+	V2Path   string
+	DecodeV1 func(json.RawMessage) (T, error)
+	DecodeV2 func(json.RawMessage) (T, error)
+}
+
+// Url returns the first page of the resource for the given version.
+func (r Resource[T]) Url(v Version) (string, error) {
+	return r.urlFrom(BaseUrl, v)
+}
+
+func (r Resource[T]) urlFrom(baseUrl string, v Version) (string, error) {
+	path := r.V1Path
+	if v == V2 {
+		path = r.V2Path
+	}
+	if !r.Available(v) {
+		return "", fmt.Errorf("%w (%s)", ErrUnavailable, v)
+	}
+	return fmt.Sprintf("%s/%s/%s/", baseUrl, v, path), nil
+}
+
+// Available reports whether the resource is served by the given version.
+func (r Resource[T]) Available(v Version) bool {
+	if v == V2 {
+		return r.V2Path != "" && r.DecodeV2 != nil
+	}
+	return r.V1Path != "" && r.DecodeV1 != nil
+}
+
+func (r Resource[T]) Decode(v Version, raw json.RawMessage) (T, error) {
+	if !r.Available(v) {
+		var item T
+		return item, fmt.Errorf("%w (%s)", ErrUnavailable, v)
+	}
+	if v == V2 {
+		return r.DecodeV2(raw)
+	}
+	return r.DecodeV1(raw)
+}
+
+// Page is the envelope both API versions wrap their results in.
+type Page struct {
+	Count    int               `json:"count"`
+	Next     string            `json:"next"`
+	Previous string            `json:"previous"`
+	Results  []json.RawMessage `json:"results"`
+}
+
+// DecodePage decodes every result on a page, dropping those the decoder
+// skips, and returns the url of the next page or "" on the last page.
+func DecodePage[T any](r Resource[T], v Version, body []byte) ([]T, string, error) {
+	var page Page
+	err := json.Unmarshal(body, &page)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var items []T
+	for i, raw := range page.Results {
+		item, err := r.Decode(v, raw)
+		if errors.Is(err, ErrSkip) {
+			continue
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("could not decode result at index %d: %w", i, err)
+		}
+		items = append(items, item)
+	}
+	return items, page.Next, nil
+}
+
+// Named is the {"name": ..., "key": ...} reference v2 uses for nested
+// resources such as documents, sizes and creature types.
+type Named struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+}
+
+var slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify makes the slug v1 gives a name, e.g. "Beyond the Material" is
+// "beyond-the-material", for the references v1 makes by name alone.
+func Slugify(name string) string {
+	name = strings.NewReplacer("'", "", "’", "").Replace(strings.ToLower(name))
+	return strings.Trim(slugSeparators.ReplaceAllString(name, "-"), "-")
+}
@@ -0,0 +1,174 @@
+package open5e
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type thing struct {
+	Slug string
+	Name string
+}
+
+var things = Resource[thing]{
+	V1Path: "things",
+	V2Path: "stuff",
+	DecodeV1: func(raw json.RawMessage) (thing, error) {
+		var t struct {
+			Slug string `json:"slug"`
+			Name string `json:"name"`
+		}
+		err := json.Unmarshal(raw, &t)
+		return thing{Slug: t.Slug, Name: t.Name}, err
+	},
+	DecodeV2: func(raw json.RawMessage) (thing, error) {
+		var t struct {
+			Key      string `json:"key"`
+			Name     string `json:"name"`
+			ParentOf *Named `json:"subthing_of"`
+		}
+		err := json.Unmarshal(raw, &t)
+		if err == nil && t.ParentOf != nil {
+			return thing{}, ErrSkip
+		}
+		return thing{Slug: t.Key, Name: t.Name}, err
+	},
+}
+
+func TestParseVersion(t *testing.T) {
+	for _, s := range []string{"v1", "v2"} {
+		v, err := ParseVersion(s)
+		if err != nil || string(v) != s {
+			t.Errorf("ParseVersion(%q) = %q, %v", s, v, err)
+		}
+	}
+	if _, err := ParseVersion("v3"); err == nil {
+		t.Error("expected an error for v3")
+	}
+}
+
+func TestUrl(t *testing.T) {
+	url, err := things.Url(V1)
+	if err != nil || url != "https://api.open5e.com/v1/things/" {
+		t.Errorf("unexpected v1 url %q, %v", url, err)
+	}
+	url, err = things.Url(V2)
+	if err != nil || url != "https://api.open5e.com/v2/stuff/" {
+		t.Errorf("unexpected v2 url %q, %v", url, err)
+	}
+
+	v1Only := Resource[thing]{V1Path: "things", DecodeV1: things.DecodeV1}
+	if _, err := v1Only.Url(V2); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable for a resource without a v2 path, got %v", err)
+	}
+	if _, err := v1Only.Decode(V2, json.RawMessage(`{}`)); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable decoding a resource without a v2 decoder, got %v", err)
+	}
+	if !v1Only.Available(V1) || v1Only.Available(V2) {
+		t.Error("expected the resource to be available in v1 only")
+	}
+}
+
+func TestDecodePage(t *testing.T) {
+	v1 := []byte(`{"count": 1, "next": "https://api.open5e.com/v1/things/?page=2", "results": [{"slug": "a", "name": "A"}]}`)
+	items, next, err := DecodePage(things, V1, v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0] != (thing{"a", "A"}) || next != "https://api.open5e.com/v1/things/?page=2" {
+		t.Errorf("unexpected v1 page %v, %q", items, next)
+	}
+
+	v2 := []byte(`{"count": 2, "next": null, "results": [{"key": "b", "name": "B"}, {"key": "c", "name": "C", "subthing_of": {"key": "b", "name": "B"}}]}`)
+	items, next, err = DecodePage(things, V2, v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0] != (thing{"b", "B"}) || next != "" {
+		t.Errorf("unexpected v2 page %v, %q", items, next)
+	}
+}
+
+func TestImport(t *testing.T) {
+	pages := map[string]string{
+		"/v1/things/":        `{"count": 3, "next": "SERVER/v1/things/?page=2", "results": [{"slug": "a"}, {"slug": "b"}]}`,
+		"/v1/things/?page=2": `{"count": 3, "next": null, "results": [{"slug": "c"}]}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(strings.ReplaceAll(page, "SERVER", "http://"+r.Host)))
+	}))
+	defer server.Close()
+
+	var slugs []string
+	// rate limited, to go through the ticker
+	client := NewClient(1000)
+	defer client.Close()
+	count, err := importFrom(client, server.URL, things, V1, func(page []thing) error {
+		for _, item := range page {
+			slugs = append(slugs, item.Slug)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 || strings.Join(slugs, ",") != "a,b,c" {
+		t.Errorf("unexpected import of %d results: %v", count, slugs)
+	}
+
+	_, err = importFrom(client, server.URL, Resource[thing]{V1Path: "missing", DecodeV1: things.DecodeV1}, V1, func([]thing) error { return nil })
+	if err == nil {
+		t.Error("expected an error for a missing resource")
+	}
+
+	// a failed write stops the import
+	boom := errors.New("boom")
+	count, err = importFrom(client, server.URL, things, V1, func([]thing) error { return boom })
+	if !errors.Is(err, boom) || count != 0 {
+		t.Errorf("expected the write's error after 0 results, got %v after %d", err, count)
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	type model struct {
+		Name        string `json:"name"`
+		HitDice     string `json:"hit_dice"`
+		Description string `json:"desc"`
+	}
+	result := map[string]interface{}{
+		"name": "A", "hit_dice": "1d8", "desc": "...", "page_no": 1,
+		"document__slug": "srd", "armor_class": 12, "img_main": nil,
+	}
+	unknown := UnknownKeys(reflect.TypeOf(model{}), result, map[string]string{"desc": "Description"})
+	if strings.Join(unknown, ",") != "armor_class,img_main" {
+		t.Errorf("unexpected unknown keys %q", unknown)
+	}
+
+	decode := DecodeV1[model](map[string]string{"desc": "Description"})
+	m, err := decode(json.RawMessage(`{"name": "A", "hit_dice": "1d8", "desc": "..."}`))
+	if err != nil || m != (model{"A", "1d8", "..."}) {
+		t.Errorf("unexpected decode %+v, %v", m, err)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	for name, slug := range map[string]string{
+		"Beyond the Material":      "beyond-the-material",
+		"Tasha's Hideous Laughter": "tashas-hideous-laughter",
+		"Actions in Combat":        "actions-in-combat",
+	} {
+		if got := Slugify(name); got != slug {
+			t.Errorf("Slugify(%q) = %q, want %q", name, got, slug)
+		}
+	}
+}
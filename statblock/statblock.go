@@ -0,0 +1,212 @@
+// Package statblock parses the movement, senses and languages lines of
+// monster and race stat blocks, e.g. a speed of {"walk": 40, "fly": 80},
+// senses of "darkvision 120 ft., passive Perception 20" and languages of
+// "Deep Speech, telepathy 120 ft.".
+package statblock
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Speed is a creature's movement in feet.  a movement the creature
+// doesn't have is nil, apart from one given as 0.  Hover is only set for
+// fliers which can hover.
+type Speed struct {
+	Walk   *int32
+	Swim   *int32
+	Fly    *int32
+	Climb  *int32
+	Burrow *int32
+	Hover  bool
+}
+
+// ParseSpeed reads the decoded JSON speed object both API versions serve,
+// e.g. {"walk": 30, "fly": 60, "hover": true}.  v2 adds a "unit" key,
+// which is ignored.  keys for movement Speed doesn't know, and speeds which
+// aren't numbers, are reported together in the error, but don't stop the
+// rest of the speeds being read.
+func ParseSpeed(v interface{}) (Speed, error) {
+	var speed Speed
+	if v == nil {
+		return speed, nil
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return speed, fmt.Errorf("speed is not an object: %v", v)
+	}
+
+	// in key order, so the error is the same each time
+	var keys []string
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	for _, key := range keys {
+		value := obj[key]
+		if key == "hover" {
+			speed.Hover, _ = value.(bool)
+			continue
+		}
+		if key == "unit" || key == "notes" {
+			continue
+		}
+
+		var feet int32
+		switch value := value.(type) {
+		case float64:
+			feet = int32(value)
+		case string:
+			// a few v1 speeds are strings such as "30 ft."
+			n, err := strconv.Atoi(strings.Fields(value + " 0")[0])
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("bad %s speed %q", key, value))
+				continue
+			}
+			feet = int32(n)
+		case nil:
+			continue
+		default:
+			problems = append(problems, fmt.Sprintf("bad %s speed %v", key, value))
+			continue
+		}
+
+		switch key {
+		case "walk":
+			speed.Walk = &feet
+		case "swim":
+			speed.Swim = &feet
+		case "fly":
+			speed.Fly = &feet
+		case "climb":
+			speed.Climb = &feet
+		case "burrow":
+			speed.Burrow = &feet
+		default:
+			problems = append(problems, fmt.Sprintf("unknown movement %q", key))
+		}
+	}
+	if len(problems) > 0 {
+		return speed, errors.New(strings.Join(problems, ", "))
+	}
+	return speed, nil
+}
+
+// Sense is a special sense and its range in feet, e.g. "blindsight 30
+// ft. (blind beyond this radius)".
+type Sense struct {
+	Type  string // "blindsight", "darkvision", "tremorsense" or "truesight"
+	Range int32
+	Note  string
+}
+
+type Senses struct {
+	Senses            []Sense
+	PassivePerception int32
+}
+
+var (
+	senseRe       = regexp.MustCompile(`(?i)\b(blindsight|darkvision|tremorsense|truesight)\s+(\d+)\s*(?:ft\.?|feet)(?:\s*\(([^)]*)\))?`)
+	passiveRe     = regexp.MustCompile(`(?i)passive perception\s+(\d+)`)
+	telepathyRe   = regexp.MustCompile(`(?i)^telepathy(?:\s+(\d+)\s*(?:ft\.?|feet))?`)
+	understandsRe = regexp.MustCompile(`(?i)understands\s+(.*?)\s*,?\s*but (?:can't|can’t|cannot|doesn't|does not) speak(?: (?:it|them|any))?`)
+	listSplitRe   = regexp.MustCompile(`\s*,\s*|\s+and\s+`)
+)
+
+// ParseSenses reads a senses line such as "blindsight 60 ft., darkvision
+// 120 ft., passive Perception 21".
+func ParseSenses(s string) Senses {
+	var senses Senses
+	for _, m := range senseRe.FindAllStringSubmatch(s, -1) {
+		n, _ := strconv.Atoi(m[2])
+		senses.Senses = append(senses.Senses, Sense{Type: strings.ToLower(m[1]), Range: int32(n), Note: m[3]})
+	}
+	if m := passiveRe.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		senses.PassivePerception = int32(n)
+	}
+	return senses
+}
+
+// Language is one entry of a languages line.  entries which aren't a
+// language's name, like "any one language (usually Common)", are kept as
+// written.  Speaks is false for a language the creature only understands.
+type Language struct {
+	Name   string
+	Speaks bool
+}
+
+type Languages struct {
+	Languages []Language
+	Telepathy int32 // range in feet, -1 for telepathy without a range
+}
+
+// ParseLanguages reads a languages line such as "Deep Speech, telepathy
+// 120 ft." or "understands Common and Giant but can't speak".  a line of
+// "—" has no languages.
+func ParseLanguages(s string) Languages {
+	var langs Languages
+	if m := understandsRe.FindStringSubmatchIndex(s); m != nil {
+		langs.add(s[m[2]:m[3]], false)
+		s = s[:m[0]] + s[m[1]:]
+	}
+	langs.add(s, true)
+	return langs
+}
+
+func (l *Languages) add(s string, speaks bool) {
+	for _, item := range splitOutsideParens(s) {
+		item = strings.TrimPrefix(strings.Trim(item, " .;"), "and ")
+		if item == "" || item == "—" || item == "-" {
+			continue
+		}
+		if m := telepathyRe.FindStringSubmatch(item); m != nil {
+			l.Telepathy = -1
+			if m[1] != "" {
+				n, _ := strconv.Atoi(m[1])
+				l.Telepathy = int32(n)
+			}
+			continue
+		}
+		for _, name := range splitNames(item) {
+			l.Languages = append(l.Languages, Language{Name: name, Speaks: speaks})
+		}
+	}
+}
+
+// splitNames splits "Common and Giant" into its languages, but keeps
+// descriptions like "the languages it knew in life" whole.
+func splitNames(item string) []string {
+	names := listSplitRe.Split(item, -1)
+	for _, name := range names {
+		if name == "" || strings.ToUpper(name[:1]) != name[:1] {
+			return []string{item}
+		}
+	}
+	return names
+}
+
+func splitOutsideParens(s string) []string {
+	var items []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',', ';':
+			if depth == 0 {
+				items = append(items, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(items, s[start:])
+}
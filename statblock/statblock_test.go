@@ -0,0 +1,101 @@
+package statblock
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseSpeed(t *testing.T) {
+	ft := func(feet int32) *int32 { return &feet }
+	cases := []struct {
+		json  string
+		speed Speed
+	}{
+		{`{"walk": 30}`, Speed{Walk: ft(30)}},
+		{`{"walk": 40, "burrow": 30, "fly": 80}`, Speed{Walk: ft(40), Burrow: ft(30), Fly: ft(80)}},
+		{`{"walk": 10, "swim": 40, "climb": 20}`, Speed{Walk: ft(10), Swim: ft(40), Climb: ft(20)}},
+		// a walking speed of 0 is kept apart from one which isn't given
+		{`{"walk": 0, "fly": 30, "hover": true}`, Speed{Walk: ft(0), Fly: ft(30), Hover: true}},
+		{`{"fly": 30, "swim": null}`, Speed{Fly: ft(30)}},
+		{`{"walk": 30, "unit": "feet"}`, Speed{Walk: ft(30)}},
+		{`null`, Speed{}},
+	}
+	for _, c := range cases {
+		var v interface{}
+		if err := json.Unmarshal([]byte(c.json), &v); err != nil {
+			t.Fatal(err)
+		}
+		speed, err := ParseSpeed(v)
+		if err != nil || !reflect.DeepEqual(speed, c.speed) {
+			t.Errorf("ParseSpeed(%s) = %+v, %v, want %+v", c.json, speed, err, c.speed)
+		}
+	}
+
+	if _, err := ParseSpeed(map[string]interface{}{"teleport": 30.0}); err == nil {
+		t.Errorf("ParseSpeed accepted an unknown movement")
+	}
+
+	// the known speeds are all read alongside unknown ones, which are
+	// reported together
+	v := map[string]interface{}{"walk": 30.0, "teleport": 30.0, "fly": 60.0, "swim": 40.0, "phase": 10.0}
+	for i := 0; i < 10; i++ {
+		speed, err := ParseSpeed(v)
+		if want := (Speed{Walk: ft(30), Fly: ft(60), Swim: ft(40)}); !reflect.DeepEqual(speed, want) {
+			t.Fatalf("ParseSpeed(%v) = %+v, want %+v", v, speed, want)
+		}
+		if err == nil || err.Error() != `unknown movement "phase", unknown movement "teleport"` {
+			t.Fatalf("ParseSpeed(%v) error %v", v, err)
+		}
+	}
+}
+
+func TestParseSenses(t *testing.T) {
+	cases := []struct {
+		s      string
+		senses Senses
+	}{
+		{"darkvision 120 ft., passive Perception 20", Senses{
+			Senses: []Sense{{Type: "darkvision", Range: 120}}, PassivePerception: 20}},
+		{"blindsight 30 ft. (blind beyond this radius), passive Perception 10", Senses{
+			Senses: []Sense{{Type: "blindsight", Range: 30, Note: "blind beyond this radius"}}, PassivePerception: 10}},
+		{"blindsight 60 ft., darkvision 120 ft., passive Perception 21", Senses{
+			Senses: []Sense{{Type: "blindsight", Range: 60}, {Type: "darkvision", Range: 120}}, PassivePerception: 21}},
+		{"passive Perception 12", Senses{PassivePerception: 12}},
+	}
+	for _, c := range cases {
+		if got := ParseSenses(c.s); !reflect.DeepEqual(got, c.senses) {
+			t.Errorf("ParseSenses(%q) = %+v, want %+v", c.s, got, c.senses)
+		}
+	}
+}
+
+func TestParseLanguages(t *testing.T) {
+	speaks := func(names ...string) []Language {
+		var langs []Language
+		for _, name := range names {
+			langs = append(langs, Language{Name: name, Speaks: true})
+		}
+		return langs
+	}
+	cases := []struct {
+		s     string
+		langs Languages
+	}{
+		{"Common, Draconic", Languages{Languages: speaks("Common", "Draconic")}},
+		{"Deep Speech, telepathy 120 ft.", Languages{Languages: speaks("Deep Speech"), Telepathy: 120}},
+		{"any one language (usually Common)", Languages{Languages: speaks("any one language (usually Common)")}},
+		{"—", Languages{}},
+		{"understands Abyssal, Celestial, Infernal, and Primordial but can't speak, telepathy 60 ft.", Languages{
+			Languages: []Language{{Name: "Abyssal"}, {Name: "Celestial"}, {Name: "Infernal"}, {Name: "Primordial"}},
+			Telepathy: 60}},
+		{"understands the languages of its creator but can't speak", Languages{
+			Languages: []Language{{Name: "the languages of its creator"}}}},
+		{"Common and Sylvan, telepathy", Languages{Languages: speaks("Common", "Sylvan"), Telepathy: -1}},
+	}
+	for _, c := range cases {
+		if got := ParseLanguages(c.s); !reflect.DeepEqual(got, c.langs) {
+			t.Errorf("ParseLanguages(%q) = %+v, want %+v", c.s, got, c.langs)
+		}
+	}
+}